@@ -12,6 +12,28 @@ const (
 	PrioritySentAtPowerSaving       = 5
 )
 
+// APNs HTTP/2 APIのアドレス
+const (
+	ProductionAddr  = "https://api.push.apple.com"
+	DevelopmentAddr = "https://api.sandbox.push.apple.com"
+)
+
+// EnvironmentはAPNsの環境をあらわす。
+type Environment string
+
+const (
+	EnvironmentProduction  Environment = "production"
+	EnvironmentDevelopment Environment = "development"
+)
+
+// EnvironmentOfはaddrが指すAPNsの環境を返す。
+func EnvironmentOf(addr string) Environment {
+	if strings.Contains(addr, ".sandbox.") {
+		return EnvironmentDevelopment
+	}
+	return EnvironmentProduction
+}
+
 const (
 	PushTypeAlert        = "alert"
 	PushTypeBackground   = "background"
@@ -19,6 +41,10 @@ const (
 	PushTypeComplication = "complication"
 	PushTypeFileProvider = "fileprovider"
 	PushTypeMdm          = "mdm"
+	PushTypeLiveActivity = "liveactivity"
+	PushTypeLocation     = "location"
+	PushTypePushToTalk   = "pushtotalk"
+	PushTypeWidgets      = "widgets"
 )
 
 type Status uint8
@@ -92,6 +118,41 @@ type Request struct {
 	Messages []*Message
 	// 1秒あたりの通知数(0以下なら無制限)
 	BandWidth int32
+	// APNsへのHTTP/2接続の使い方(nilならスレーブのデフォルト)
+	Connection *ConnectionPolicy
+	// trueの場合、PushTypeとPriorityが空のメッセージはTopicとPayloadから推測した値で送信する。
+	// 明示された値が推測と矛盾する場合はWarningsで返す。Messagesそのものは変更しない。(APNs HTTP/2 only)
+	InferPushType bool
+
+	// trueの場合、BadDeviceTokenまたはBadCertificateEnvironmentで失敗したメッセージを
+	// SandboxAddrへ再送する。開発環境でも拒否された場合に限りトークン無効として扱う。
+	// Addrが開発環境の場合は再送しない。(APNs HTTP/2 only)
+	SandboxFallback bool
+	// 再送先の開発環境のアドレス(空ならDevelopmentAddr)
+	SandboxAddr string
+
+	// TooManyRequestsで待機中のトークンへの送信を待つ最大時間。
+	// 待機の解除がこれより先になる場合は送信せずにDeferredMessagesで返す。(APNs HTTP/2 only)
+	MaxBackoffWait time.Duration
+}
+
+// ConnectionPolicyはスレーブがAPNsへのHTTP/2接続をどのようにプールするかをあらわす。
+// ゼロ値のフィールドはスレーブのデフォルトに従う。(APNs HTTP/2 only)
+// プールは同じ接続先・資格情報・ポリシーのリクエスト間で共有し、接続を開いたままにする。
+type ConnectionPolicy struct {
+	// 同時に開くHTTP/2接続の数(0以下なら1)
+	Connections int32
+	// 1接続あたりの最大同時ストリーム数(0以下なら100)
+	// APNsが通知するSETTINGS_MAX_CONCURRENT_STREAMSの方が小さい場合はそちらに従う。
+	MaxConcurrentStreams int32
+	// 受信が途絶えてからPINGで接続を確認するまでの時間(0ならPINGしない)
+	PingInterval time.Duration
+	// PINGの応答を待つ時間(0なら15秒)
+	PingTimeout time.Duration
+	// IdleTimeoutやShutdown、GOAWAYなどで接続が閉じられた場合に、再接続して再送する最大回数(0なら再送しない)
+	MaxReconnects int32
+	// 再接続するまでの待ち時間
+	ReconnectDelay time.Duration
 }
 
 type Message struct {
@@ -107,9 +168,15 @@ type Message struct {
 	// APNs HTTP/2 only
 	Topic      string
 	CollapseID string
+	// apns-id (空ならスレーブが生成する)
+	APNsID string
 
 	// for iOS 13~, watchOS 6~ (APNs HTTP/2 only
 	PushType string
+
+	// ブロードキャストプッシュのチャネルID。
+	// セットした場合はTokenの代わりにチャネルへ送信する。(APNs HTTP/2 only)
+	ChannelID string
 }
 
 // FailedMessageは送信失敗したメッセージとその理由をあらわす。
@@ -119,6 +186,9 @@ type FailedMessage struct {
 	ErrorString string
 	// APNsプロトコルにおけるエラーの場合にセット
 	Detail *ProtocolError
+	// 送信に使ったapns-id(APNs HTTP/2 only)
+	// ErrorStringの場合でも、APNsへ届いていればAppleの配信ログツールで追跡できる。
+	APNsID string
 	// リクエストしたメッセージ
 	Message *Message
 }
@@ -128,6 +198,39 @@ type Response struct {
 	// 送信失敗したメッセージと理由。
 	// すべて成功した場合は空の配列。
 	FailedMessages []*FailedMessage
+	// 送信成功したメッセージの受領情報。(APNs HTTP/2 only)
+	Receipts []*Receipt
+	// TooManyRequestsのため送信を見送ったメッセージ。(APNs HTTP/2 only)
+	// 失敗ではないのでFailedMessagesには含まれない。
+	DeferredMessages []*DeferredMessage
+	// 明示されたapns-push-typeや優先度がペイロードと矛盾していたメッセージ。
+	// Request.InferPushTypeがtrueの場合のみ。
+	Warnings []*PushTypeWarning
+}
+
+// DeferredMessageはトークンごとの送信頻度制限により送信を見送ったメッセージをあらわす。
+type DeferredMessage struct {
+	// このメッセージを再送してよい時刻
+	RetryAfter time.Time
+	// APNsがTooManyRequestsを返した場合にセット(待機中のため送信しなかった場合はnil)
+	Detail *ProtocolError
+	// trueの場合、同じトークンとCollapseIDを持つ後続のメッセージに置き換えられたため再送は不要
+	Coalesced bool
+	// リクエストしたメッセージ
+	Message *Message
+}
+
+// ReceiptはAPNsが受け付けたメッセージの識別子をあらわす。
+type Receipt struct {
+	// APNsが返したapns-id
+	APNsID string
+	// APNsが返したapns-unique-id(開発環境のみ)
+	// Appleの配信ログツールで配信状況を追跡するのに使う。
+	UniqueID string
+	// メッセージを受け付けた環境
+	Environment Environment
+	// リクエストしたメッセージ
+	Message *Message
 }
 
 type ProtocolError struct {
@@ -138,8 +241,11 @@ type ProtocolError struct {
 
 	// HTTP/2
 	StatusCode int
-	Reason     string
+	Reason     string // ReasonCodeで型付きのReasonを得られる
 	Time       time.Time
+	APNsID     string // apns-id
+	UniqueID   string // apns-unique-id(開発環境のみ)
+	RequestID  string // apns-request-id(チャネル管理APIのみ)
 }
 
 func (e *ProtocolError) Error() string {
@@ -151,10 +257,16 @@ func (e *ProtocolError) Error() string {
 	}
 }
 
+// ReasonCodeはReasonをReason型で返す。
+// Reasonの分類メソッドを使う場合はこちらを使う。
+func (e *ProtocolError) ReasonCode() Reason {
+	return Reason(e.Reason)
+}
+
 func (e *ProtocolError) InvalidToken() (ret bool) {
 	if e.StatusCode > 0 {
 		// APNs + HTTP/2
-		ret = isInvalidToken(e.StatusCode, e.Reason)
+		ret = isInvalidToken(e.StatusCode, e.ReasonCode())
 	} else {
 		// Legacy
 		ret = e.Status == InvalidToken
@@ -0,0 +1,398 @@
+// Package apnstest provides an in-process APNs HTTP/2 server for testing.
+package apnstest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+// Notificationはサーバが受け付けた通知をあらわす。
+type Notification struct {
+	// hexエンコードされたデバイストークン
+	Token string
+	// apns-id(リクエストに無ければサーバが生成した値)
+	APNsID string
+	// サーバが割り当てたapns-unique-id
+	UniqueID string
+
+	Topic      string
+	PushType   string
+	Priority   int
+	Expiration int64
+	CollapseID string
+	Header     http.Header
+	Payload    []byte
+
+	// JWT認証の場合はiss、証明書認証の場合はSubjectのCommonName
+	Issuer string
+}
+
+// Responseは通知に対してサーバが返すレスポンスをあらわす。
+type Response struct {
+	StatusCode int
+	Reason     apns.Reason
+	// 410の場合にtimestampとして返す時刻(ゼロ値なら返さない)
+	Timestamp time.Time
+	// trueならレスポンスを返さずに、GOAWAYを送って接続を閉じる。
+	// 一度GOAWAYを送ると設定を取り除き、次からは成功を返す。
+	GoAway bool
+}
+
+// BadDeviceTokenは400 BadDeviceTokenのResponseを返す。
+func BadDeviceToken() *Response {
+	return &Response{StatusCode: http.StatusBadRequest, Reason: apns.ReasonBadDeviceToken}
+}
+
+// Unregisteredはtの時点で無効になったことを示す410 UnregisteredのResponseを返す。
+func Unregistered(t time.Time) *Response {
+	return &Response{StatusCode: http.StatusGone, Reason: apns.ReasonUnregistered, Timestamp: t}
+}
+
+// TooManyRequestsは429 TooManyRequestsのResponseを返す。
+func TooManyRequests() *Response {
+	return &Response{StatusCode: http.StatusTooManyRequests, Reason: apns.ReasonTooManyRequests}
+}
+
+// Shutdownは503 ShutdownのResponseを返す。
+func Shutdown() *Response {
+	return &Response{StatusCode: http.StatusServiceUnavailable, Reason: apns.ReasonShutdown}
+}
+
+// GoAwayは処理中のストリームにレスポンスを返さずに、GOAWAYを送って接続を閉じるResponseを返す。
+func GoAway() *Response {
+	return &Response{GoAway: true}
+}
+
+// providerKeyはJWTの検証に使う鍵をあらわす。
+type providerKey struct {
+	Issuer string
+	Key    *ecdsa.PublicKey
+}
+
+// ServerはAPNs HTTP/2 APIを模倣するTLSサーバをあらわす。
+// ヘッダとJWTまたはクライアント証明書による認証を検査して、
+// 受け付けた通知を記録する。
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	keys          map[string]*providerKey // key ID
+	certs         []*x509.Certificate
+	responses     map[string]*Response // hexエンコードされたトークン
+	notifications []*Notification
+}
+
+// NewServerは起動済みのServerを返す。
+// 利用を終えたらCloseを呼ぶこと。
+func NewServer() *Server {
+	s := &Server{
+		keys:      make(map[string]*providerKey),
+		responses: make(map[string]*Response),
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	s.Server.EnableHTTP2 = true
+	s.Server.TLS = &tls.Config{
+		ClientAuth: tls.RequestClientCert,
+	}
+	s.Server.Config.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		return context.WithValue(ctx, connKey{}, c)
+	}
+	s.Server.StartTLS()
+	return s
+}
+
+// AddCredentialはcredの資格情報で認証されたリクエストを受け付けるようにする。
+// JWTの場合はKeyIDとIssuerで署名を検証する。
+// 証明書の場合は一度でも登録すると、登録した証明書以外を拒否する。
+func (s *Server) AddCredential(cred *apns.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cred.HasProviderToken() {
+		key, err := apns.ParsePrivateKey(cred.PrivateKey)
+		if err != nil {
+			return err
+		}
+		s.keys[cred.KeyID] = &providerKey{Issuer: cred.Issuer, Key: &key.PublicKey}
+	}
+	if len(cred.CertPEMBlock) > 0 {
+		cert, err := tls.X509KeyPair(cred.CertPEMBlock, cred.KeyPEMBlock)
+		if err != nil {
+			return err
+		}
+		c, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return err
+		}
+		s.certs = append(s.certs, c)
+	}
+	return nil
+}
+
+// SetResponseはhexエンコードされたtokenへの通知に対して返すレスポンスを設定する。
+// respがnilなら設定を取り除き、成功を返すようにする。
+func (s *Server) SetResponse(token string, resp *Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token = strings.ToLower(token)
+	if resp == nil {
+		delete(s.responses, token)
+		return
+	}
+	s.responses[token] = resp
+}
+
+// Notificationsは受け付けた通知を受信した順に返す。
+// 成功・失敗に関わらず、検査を通過した通知をすべて含む。
+func (s *Server) Notifications() []*Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := make([]*Notification, len(s.notifications))
+	copy(a, s.notifications)
+	return a
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("apns-id")
+	if id == "" || !apns.IsValidAPNsID(id) {
+		id = apns.NewAPNsID()
+	}
+	uniqueID := apns.NewAPNsID()
+	w.Header().Set("apns-id", id)
+	w.Header().Set("apns-unique-id", uniqueID)
+
+	n, e := s.parse(r)
+	if e == nil {
+		n.APNsID = id
+		n.UniqueID = uniqueID
+		s.mu.Lock()
+		s.notifications = append(s.notifications, n)
+		e = s.responses[n.Token]
+		if e != nil && e.GoAway {
+			delete(s.responses, n.Token)
+		}
+		s.mu.Unlock()
+	}
+	switch {
+	case e == nil:
+		w.WriteHeader(http.StatusOK)
+	case e.GoAway:
+		goAway(r)
+	default:
+		writeError(w, e)
+	}
+}
+
+// connKeyはリクエストを受け付けた接続をContextに保存するキーをあらわす。
+type connKey struct{}
+
+// goAwayはrを受け付けた接続にGOAWAYを送って閉じる。
+// すべてのストリームを処理済みとするので、応答待ちのリクエストはクライアントでエラーになる。
+func goAway(r *http.Request) {
+	c := r.Context().Value(connKey{}).(net.Conn)
+	frame := []byte{
+		0, 0, 8, // length
+		0x7,        // type: GOAWAY
+		0,          // flags
+		0, 0, 0, 0, // stream ID
+		0x7f, 0xff, 0xff, 0xff, // last stream ID
+		0, 0, 0, 0, // error code: NO_ERROR
+	}
+	c.Write(frame)
+	c.Close()
+}
+
+func writeError(w http.ResponseWriter, e *Response) {
+	v := struct {
+		Reason    apns.Reason `json:"reason"`
+		Timestamp int64       `json:"timestamp,omitempty"`
+	}{
+		Reason: e.Reason,
+	}
+	if e.StatusCode == http.StatusGone && !e.Timestamp.IsZero() {
+		v.Timestamp = e.Timestamp.UnixNano() / int64(time.Millisecond)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.StatusCode)
+	json.NewEncoder(w).Encode(&v)
+}
+
+func newError(statusCode int, reason apns.Reason) *Response {
+	return &Response{StatusCode: statusCode, Reason: reason}
+}
+
+func (s *Server) parse(r *http.Request) (*Notification, *Response) {
+	if r.ProtoMajor != 2 {
+		return nil, newError(http.StatusBadRequest, apns.ReasonBadPath)
+	}
+	if r.Method != http.MethodPost {
+		return nil, newError(http.StatusMethodNotAllowed, apns.ReasonMethodNotAllowed)
+	}
+	const prefix = "/3/device/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		return nil, newError(http.StatusNotFound, apns.ReasonBadPath)
+	}
+	token := r.URL.Path[len(prefix):]
+	if token == "" {
+		return nil, newError(http.StatusBadRequest, apns.ReasonMissingDeviceToken)
+	}
+	if _, err := hex.DecodeString(token); err != nil {
+		return nil, newError(http.StatusBadRequest, apns.ReasonBadDeviceToken)
+	}
+	n := &Notification{
+		Token:      strings.ToLower(token),
+		Topic:      r.Header.Get("apns-topic"),
+		PushType:   r.Header.Get("apns-push-type"),
+		CollapseID: r.Header.Get("apns-collapse-id"),
+		Header:     r.Header,
+	}
+	issuer, e := s.authenticate(r)
+	if e != nil {
+		return nil, e
+	}
+	n.Issuer = issuer
+	if e := parseHeader(r.Header, n); e != nil {
+		return nil, e
+	}
+	if r.Header.Get("Authorization") != "" && n.Topic == "" {
+		return nil, newError(http.StatusBadRequest, apns.ReasonMissingTopic)
+	}
+
+	limit := apns.MaxPayloadSizeFor(n.PushType)
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, apns.ReasonBadPath)
+	}
+	switch {
+	case len(b) == 0:
+		return nil, newError(http.StatusBadRequest, apns.ReasonPayloadEmpty)
+	case len(b) > limit:
+		return nil, newError(http.StatusRequestEntityTooLarge, apns.ReasonPayloadTooLarge)
+	}
+	n.Payload = b
+	return n, nil
+}
+
+func parseHeader(h http.Header, n *Notification) *Response {
+	if n.PushType != "" && !apns.IsKnownPushType(n.PushType) {
+		return newError(http.StatusBadRequest, apns.ReasonInvalidPushType)
+	}
+	if s := h.Get("apns-priority"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v != 1 && v != 5 && v != 10 {
+			return newError(http.StatusBadRequest, apns.ReasonBadPriority)
+		}
+		n.Priority = v
+	}
+	if s := h.Get("apns-expiration"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			return newError(http.StatusBadRequest, apns.ReasonBadExpirationDate)
+		}
+		n.Expiration = v
+	}
+	if s := h.Get("apns-id"); s != "" && !apns.IsValidAPNsID(s) {
+		return newError(http.StatusBadRequest, apns.ReasonBadMessageID)
+	}
+	if len(n.CollapseID) > 64 {
+		return newError(http.StatusBadRequest, apns.ReasonBadCollapseID)
+	}
+	for k, v := range h {
+		if strings.HasPrefix(strings.ToLower(k), "apns-") && len(v) > 1 {
+			return newError(http.StatusBadRequest, apns.ReasonDuplicateHeaders)
+		}
+	}
+	return nil
+}
+
+// authenticateはrの認証情報を検査して、認証された発行者を返す。
+func (s *Server) authenticate(r *http.Request) (string, *Response) {
+	auth := r.Header.Get("Authorization")
+	if auth != "" {
+		return s.verifyToken(auth)
+	}
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "", newError(http.StatusForbidden, apns.ReasonMissingProviderToken)
+	}
+	cert := r.TLS.PeerCertificates[0]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.certs) == 0 {
+		return cert.Subject.CommonName, nil
+	}
+	for _, c := range s.certs {
+		if c.Equal(cert) {
+			return cert.Subject.CommonName, nil
+		}
+	}
+	return "", newError(http.StatusForbidden, apns.ReasonBadCertificate)
+}
+
+func (s *Server) verifyToken(auth string) (string, *Response) {
+	invalid := newError(http.StatusForbidden, apns.ReasonInvalidProviderToken)
+	const prefix = "bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", invalid
+	}
+	a := strings.Split(auth[len(prefix):], ".")
+	if len(a) != 3 {
+		return "", invalid
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+	}
+	if decodeSegment(a[0], &header) != nil || decodeSegment(a[1], &claims) != nil || header.Alg != "ES256" {
+		return "", invalid
+	}
+	s.mu.Lock()
+	key := s.keys[header.Kid]
+	s.mu.Unlock()
+	if key == nil || key.Issuer != claims.Iss {
+		return "", invalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(a[2])
+	if err != nil || len(sig) != 64 {
+		return "", invalid
+	}
+	sum := sha256.Sum256([]byte(a[0] + "." + a[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	v := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(key.Key, sum[:], r, v) {
+		return "", invalid
+	}
+	if time.Since(time.Unix(claims.Iat, 0)) > apns.TokenMaxLifetime {
+		return "", newError(http.StatusForbidden, apns.ReasonExpiredProviderToken)
+	}
+	return claims.Iss, nil
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
@@ -0,0 +1,216 @@
+package apnstest_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/apns/apnstest"
+	"github.com/BoltzEngine/apis/boltz/apns/client"
+)
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+}
+
+func newJWTCredential(t *testing.T, keyID string) *apns.Credential {
+	_, b := newKey(t)
+	return &apns.Credential{
+		Issuer:             "TEAMID",
+		KeyID:              keyID,
+		PrivateKey:         b,
+		InsecureSkipVerify: true,
+	}
+}
+
+func newCertCredential(t *testing.T, name string) *apns.Credential {
+	key, keyPEM := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &apns.Credential{
+		CertPEMBlock:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEMBlock:        keyPEM,
+		InsecureSkipVerify: true,
+	}
+}
+
+func send(t *testing.T, s *apnstest.Server, cred *apns.Credential, messages ...*apns.Message) []*apns.FailedMessage {
+	t.Helper()
+	resp := do(t, s, cred, messages...)
+	return resp.FailedMessages
+}
+
+func do(t *testing.T, s *apnstest.Server, cred *apns.Credential, messages ...*apns.Message) *apns.Response {
+	t.Helper()
+	var c client.Client
+	resp, err := c.Do(context.Background(), &apns.Request{
+		Addr:       s.URL,
+		Credential: cred,
+		Messages:   messages,
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	return resp
+}
+
+func TestServerResponses(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	cred := newJWTCredential(t, "KEY1")
+	if err := s.AddCredential(cred); err != nil {
+		t.Fatal(err)
+	}
+	gone := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	s.SetResponse("02", apnstest.Unregistered(gone))
+	s.SetResponse("03", apnstest.TooManyRequests())
+	s.SetResponse("04", apnstest.Shutdown())
+	s.SetResponse("05", apnstest.BadDeviceToken())
+
+	newMessage := func(token byte) *apns.Message {
+		return &apns.Message{
+			Token:    []byte{token},
+			Topic:    "com.example.app",
+			PushType: apns.PushTypeAlert,
+			Priority: apns.PrioritySentImmediately,
+			Payload:  []byte(`{"aps":{"alert":"hello"}}`),
+		}
+	}
+	resp := do(t, s, cred, newMessage(1), newMessage(2), newMessage(3), newMessage(4), newMessage(5))
+	failures := resp.FailedMessages
+	if len(failures) != 3 {
+		t.Fatalf("len(FailedMessages) = %d; want 3", len(failures))
+	}
+	tab := []struct {
+		StatusCode   int
+		Reason       apns.Reason
+		InvalidToken bool
+		Timestamp    time.Time
+	}{
+		{StatusCode: http.StatusGone, Reason: apns.ReasonUnregistered, InvalidToken: true, Timestamp: gone},
+		{StatusCode: http.StatusServiceUnavailable, Reason: apns.ReasonShutdown},
+		{StatusCode: http.StatusBadRequest, Reason: apns.ReasonBadDeviceToken, InvalidToken: true},
+	}
+	for i, v := range tab {
+		e := failures[i].Detail
+		if e == nil {
+			t.Errorf("FailedMessages[%d] = %q; want ProtocolError", i, failures[i].ErrorString)
+			continue
+		}
+		if e.StatusCode != v.StatusCode || e.ReasonCode() != v.Reason || e.InvalidToken() != v.InvalidToken {
+			t.Errorf("FailedMessages[%d] = %d %s (InvalidToken=%v); want %d %s (InvalidToken=%v)",
+				i, e.StatusCode, e.Reason, e.InvalidToken(), v.StatusCode, v.Reason, v.InvalidToken)
+		}
+		if !v.Timestamp.IsZero() && !e.Timestamp().Equal(v.Timestamp) {
+			t.Errorf("FailedMessages[%d].Timestamp() = %v; want %v", i, e.Timestamp(), v.Timestamp)
+		}
+	}
+
+	// TooManyRequestsは失敗ではなく見送りとして返る
+	if len(resp.DeferredMessages) != 1 {
+		t.Fatalf("len(DeferredMessages) = %d; want 1", len(resp.DeferredMessages))
+	}
+	if e := resp.DeferredMessages[0].Detail; e == nil || e.StatusCode != http.StatusTooManyRequests || e.ReasonCode() != apns.ReasonTooManyRequests {
+		t.Errorf("DeferredMessages[0].Detail = %+v; want %d %s", e, http.StatusTooManyRequests, apns.ReasonTooManyRequests)
+	}
+
+	a := s.Notifications()
+	if len(a) != 5 {
+		t.Fatalf("len(Notifications) = %d; want 5", len(a))
+	}
+	if n := a[0]; n.Issuer != "TEAMID" || n.Priority != 10 || n.PushType != apns.PushTypeAlert {
+		t.Errorf("Notifications[0] = %+v", n)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	jwt := newJWTCredential(t, "KEY1")
+	cert := newCertCredential(t, "Apple Push Services: com.example.app")
+	for _, cred := range []*apns.Credential{jwt, cert} {
+		if err := s.AddCredential(cred); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &apns.Message{Token: []byte{1}, Topic: "com.example.app", Payload: []byte(`{}`)}
+
+	tab := []struct {
+		Name   string
+		Cred   *apns.Credential
+		Reason apns.Reason
+	}{
+		{Name: "registered key", Cred: jwt},
+		{Name: "unknown key", Cred: newJWTCredential(t, "KEY2"), Reason: apns.ReasonInvalidProviderToken},
+		{Name: "registered certificate", Cred: cert},
+		{Name: "unknown certificate", Cred: newCertCredential(t, "other"), Reason: apns.ReasonBadCertificate},
+		{Name: "no credential", Cred: &apns.Credential{InsecureSkipVerify: true}, Reason: apns.ReasonMissingProviderToken},
+	}
+	for _, v := range tab {
+		failures := send(t, s, v.Cred, m)
+		switch {
+		case v.Reason == "" && len(failures) != 0:
+			t.Errorf("%s: FailedMessages = %+v; want success", v.Name, failures[0])
+		case v.Reason != "" && (len(failures) != 1 || failures[0].Detail == nil || failures[0].Detail.ReasonCode() != v.Reason):
+			t.Errorf("%s: FailedMessages = %+v; want %s", v.Name, failures, v.Reason)
+		}
+	}
+}
+
+func TestServerAPNsID(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	cred := newCertCredential(t, "Apple Push Services: com.example.app")
+	s.SetResponse("02", apnstest.Unregistered(time.Now()))
+
+	id := apns.NewAPNsID()
+	resp := do(t, s, cred,
+		&apns.Message{Token: []byte{1}, APNsID: id, Payload: []byte(`{}`)},
+		&apns.Message{Token: []byte{2}, Payload: []byte(`{}`)},
+	)
+	if len(resp.Receipts) != 1 || len(resp.FailedMessages) != 1 {
+		t.Fatalf("Do = %d receipts, %d failures; want 1, 1", len(resp.Receipts), len(resp.FailedMessages))
+	}
+	a := make(map[string]*apnstest.Notification)
+	for _, n := range s.Notifications() {
+		a[n.Token] = n
+	}
+	if r, n := resp.Receipts[0], a["01"]; r.APNsID != id || r.UniqueID != n.UniqueID || r.UniqueID == "" {
+		t.Errorf("Receipt = %+v; want apns-id %s and apns-unique-id %s", r, id, n.UniqueID)
+	}
+	if e, n := resp.FailedMessages[0].Detail, a["02"]; e == nil || e.APNsID != n.APNsID || !apns.IsValidAPNsID(e.APNsID) || e.UniqueID != n.UniqueID {
+		t.Errorf("Detail = %+v; want apns-id %s and apns-unique-id %s", e, n.APNsID, n.UniqueID)
+	}
+
+	failures := send(t, s, cred, &apns.Message{Token: []byte{1}, APNsID: "not-a-uuid", Payload: []byte(`{}`)})
+	if len(failures) != 1 || failures[0].Detail == nil || failures[0].Detail.ReasonCode() != apns.ReasonBadMessageID {
+		t.Errorf("FailedMessages = %+v; want %s", failures, apns.ReasonBadMessageID)
+	}
+}
@@ -0,0 +1,291 @@
+package apns
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+Legacy binary protocol.
+  The binary interface was discontinued by Apple. This codec exists only to
+  replay captured traffic and to implement stand-in servers for old appliances.
+  https://developer.apple.com/library/archive/documentation/NetworkingInternet/Conceptual/RemoteNotificationsPG/BinaryProviderAPI.html
+*/
+
+// バイナリプロトコルのコマンド
+const (
+	CommandNotification  uint8 = 2
+	CommandErrorResponse uint8 = 8
+)
+
+const (
+	// バイナリプロトコルのデバイストークンのサイズ
+	LegacyTokenSize = 32
+	// バイナリプロトコルのペイロードの最大サイズ
+	MaxLegacyPayloadSize = 2048
+
+	// エラーレスポンスパケットのサイズ
+	errorResponseSize = 6
+	// 通知フレームの最大サイズ(これを超えるフレームは読まない)
+	maxFrameSize = 1 << 16
+)
+
+// 通知フレームのアイテムID
+const (
+	itemToken      uint8 = 1
+	itemPayload    uint8 = 2
+	itemID         uint8 = 3
+	itemExpiration uint8 = 4
+	itemPriority   uint8 = 5
+)
+
+var (
+	errFrameTooLarge = errors.New("apns: frame too large")
+	errShortItem     = errors.New("apns: item exceeds frame")
+)
+
+// UnknownCommandErrorは読み込んだパケットのコマンドが期待と異なることをあらわす。
+type UnknownCommandError struct {
+	Cmd uint8
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("apns: unknown command %d", e.Cmd)
+}
+
+// WriteNotificationはmをコマンド2の通知フレームとしてwに書き込む。
+// TokenとPayloadのサイズはバイナリプロトコルの制限を超えてはならない。
+// Priorityが0の場合は優先度アイテムを省略する。
+func WriteNotification(w io.Writer, m *Message) error {
+	if len(m.Token) != LegacyTokenSize {
+		return fmt.Errorf("apns: token size %d; want %d", len(m.Token), LegacyTokenSize)
+	}
+	if len(m.Payload) == 0 {
+		return errors.New("apns: missing payload")
+	}
+	if len(m.Payload) > MaxLegacyPayloadSize {
+		return &PayloadSizeError{Size: len(m.Payload), Limit: MaxLegacyPayloadSize}
+	}
+	var id, expir [4]byte
+	binary.BigEndian.PutUint32(id[:], m.ID)
+	binary.BigEndian.PutUint32(expir[:], m.Expir)
+
+	var frame []byte
+	frame = appendItem(frame, itemToken, m.Token)
+	frame = appendItem(frame, itemPayload, m.Payload)
+	frame = appendItem(frame, itemID, id[:])
+	frame = appendItem(frame, itemExpiration, expir[:])
+	if m.Priority != 0 {
+		frame = appendItem(frame, itemPriority, []byte{m.Priority})
+	}
+
+	b := make([]byte, 5, 5+len(frame))
+	b[0] = CommandNotification
+	binary.BigEndian.PutUint32(b[1:], uint32(len(frame)))
+	_, err := w.Write(append(b, frame...))
+	return err
+}
+
+func appendItem(b []byte, id uint8, data []byte) []byte {
+	var h [3]byte
+	h[0] = id
+	binary.BigEndian.PutUint16(h[1:], uint16(len(data)))
+	return append(append(b, h[:]...), data...)
+}
+
+// ReadNotificationはrからコマンド2の通知フレームをひとつ読み込む。
+//
+// フレームは読めたが内容がバイナリプロトコルの規則に反する場合は、
+// 読み込んだMessageと共に、APNsが返すはずの*ProtocolErrorを返す。
+// スタンドインサーバはこれをそのままWriteErrorResponseに渡せばよい。
+// ストリームの終端では io.EOF を返す。
+func ReadNotification(r io.Reader) (*Message, error) {
+	var h [5]byte
+	if _, err := io.ReadFull(r, h[:1]); err != nil {
+		return nil, err
+	}
+	if h[0] != CommandNotification {
+		return nil, &UnknownCommandError{Cmd: h[0]}
+	}
+	if _, err := io.ReadFull(r, h[1:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	n := binary.BigEndian.Uint32(h[1:])
+	if n > maxFrameSize {
+		return nil, errFrameTooLarge
+	}
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	var (
+		m          Message
+		hasToken   bool
+		hasPayload bool
+		status     = Success
+	)
+	for len(frame) > 0 {
+		if len(frame) < 3 {
+			return nil, errShortItem
+		}
+		id := frame[0]
+		size := int(binary.BigEndian.Uint16(frame[1:3]))
+		if len(frame) < 3+size {
+			return nil, errShortItem
+		}
+		data := frame[3 : 3+size]
+		frame = frame[3+size:]
+
+		switch id {
+		case itemToken:
+			hasToken = true
+			m.Token = data
+			if size != LegacyTokenSize {
+				status = firstStatus(status, InvalidTokenSize)
+			}
+		case itemPayload:
+			hasPayload = size > 0
+			m.Payload = data
+			if size > MaxLegacyPayloadSize {
+				status = firstStatus(status, InvalidPayloadSize)
+			}
+		case itemID:
+			if size != 4 {
+				status = firstStatus(status, ProcessingError)
+				continue
+			}
+			m.ID = binary.BigEndian.Uint32(data)
+		case itemExpiration:
+			if size != 4 {
+				status = firstStatus(status, ProcessingError)
+				continue
+			}
+			m.Expir = binary.BigEndian.Uint32(data)
+		case itemPriority:
+			if size != 1 {
+				status = firstStatus(status, ProcessingError)
+				continue
+			}
+			m.Priority = data[0]
+			if m.Priority != PrioritySentImmediately && m.Priority != PrioritySentAtPowerSaving {
+				status = firstStatus(status, ProcessingError)
+			}
+		default:
+			status = firstStatus(status, ProcessingError)
+		}
+	}
+	switch {
+	case !hasToken:
+		status = MissingToken
+	case !hasPayload:
+		status = MissingPayload
+	}
+	if status != Success {
+		return &m, &ProtocolError{Cmd: CommandErrorResponse, Status: status, ID: m.ID}
+	}
+	return &m, nil
+}
+
+func firstStatus(cur, status Status) Status {
+	if cur != Success {
+		return cur
+	}
+	return status
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// WriteErrorResponseはeをコマンド8のエラーレスポンスパケットとしてwに書き込む。
+func WriteErrorResponse(w io.Writer, e *ProtocolError) error {
+	var b [errorResponseSize]byte
+	b[0] = CommandErrorResponse
+	b[1] = uint8(e.Status)
+	binary.BigEndian.PutUint32(b[2:], e.ID)
+	_, err := w.Write(b[:])
+	return err
+}
+
+// ReadErrorResponseはrからコマンド8のエラーレスポンスパケットをひとつ読み込む。
+// ストリームの終端では io.EOF を返す。
+func ReadErrorResponse(r io.Reader) (*ProtocolError, error) {
+	var b [errorResponseSize]byte
+	if _, err := io.ReadFull(r, b[:1]); err != nil {
+		return nil, err
+	}
+	if b[0] != CommandErrorResponse {
+		return nil, &UnknownCommandError{Cmd: b[0]}
+	}
+	if _, err := io.ReadFull(r, b[1:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return &ProtocolError{
+		Cmd:    b[0],
+		Status: Status(b[1]),
+		ID:     binary.BigEndian.Uint32(b[2:]),
+	}, nil
+}
+
+// WriteFeedbackはfbをフィードバックサービスのタプルとしてwに書き込む。
+func WriteFeedback(w io.Writer, fb *Feedback) error {
+	if len(fb.Token) > 0xffff {
+		return fmt.Errorf("apns: token size %d too large", len(fb.Token))
+	}
+	b := make([]byte, 6, 6+len(fb.Token))
+	binary.BigEndian.PutUint32(b, fb.Timestamp)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(fb.Token)))
+	_, err := w.Write(append(b, fb.Token...))
+	return err
+}
+
+// ReadFeedbackはrからフィードバックサービスのタプルをひとつ読み込む。
+// ストリームの終端では io.EOF を返す。
+func ReadFeedback(r io.Reader) (*Feedback, error) {
+	var h [6]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return nil, err
+	}
+	token := make([]byte, binary.BigEndian.Uint16(h[4:]))
+	if _, err := io.ReadFull(r, token); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return &Feedback{
+		Timestamp: binary.BigEndian.Uint32(h[:4]),
+		Token:     token,
+	}, nil
+}
+
+// WriteFBResponseはresp.Bodyのすべてのタプルをwに書き込む。
+func WriteFBResponse(w io.Writer, resp *FBResponse) error {
+	bw := bufio.NewWriter(w)
+	for _, fb := range resp.Body {
+		if err := WriteFeedback(bw, fb); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadFBResponseはrが終端に達するまでタプルを読み込む。
+func ReadFBResponse(r io.Reader) (*FBResponse, error) {
+	br := bufio.NewReader(r)
+	resp := &FBResponse{Body: []*Feedback{}}
+	for {
+		fb, err := ReadFeedback(br)
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = append(resp.Body, fb)
+	}
+}
@@ -0,0 +1,124 @@
+package apns
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestNotificationFrame(t *testing.T) {
+	m := &Message{
+		ID:       0x01020304,
+		Expir:    1600000000,
+		Token:    bytes.Repeat([]byte{0xab}, LegacyTokenSize),
+		Payload:  []byte(`{"aps":{"alert":"hi"}}`),
+		Priority: PrioritySentImmediately,
+	}
+	var buf bytes.Buffer
+	if err := WriteNotification(&buf, m); err != nil {
+		t.Fatalf("WriteNotification: %v", err)
+	}
+	b := buf.Bytes()
+	if b[0] != CommandNotification {
+		t.Errorf("command = %d; want %d", b[0], CommandNotification)
+	}
+	// token(3+32) + payload(3+22) + id(3+4) + expir(3+4) + priority(3+1)
+	if want := "0000004e"; hex.EncodeToString(b[1:5]) != want {
+		t.Errorf("frame length = %x; want %s", b[1:5], want)
+	}
+
+	got, err := ReadNotification(&buf)
+	if err != nil {
+		t.Fatalf("ReadNotification: %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("ReadNotification = %+v; want %+v", got, m)
+	}
+	if _, err := ReadNotification(&buf); err != io.EOF {
+		t.Errorf("ReadNotification at end = %v; want EOF", err)
+	}
+}
+
+func TestReadNotificationError(t *testing.T) {
+	token := bytes.Repeat([]byte{1}, LegacyTokenSize)
+	id := []byte{0, 0, 0, 7}
+	tab := []struct {
+		Name   string
+		Items  [][]byte
+		Status Status
+	}{
+		{Name: "missing token", Items: [][]byte{item(itemPayload, []byte(`{}`)), item(itemID, id)}, Status: MissingToken},
+		{Name: "missing payload", Items: [][]byte{item(itemToken, token), item(itemID, id)}, Status: MissingPayload},
+		{Name: "short token", Items: [][]byte{item(itemToken, token[:8]), item(itemPayload, []byte(`{}`)), item(itemID, id)}, Status: InvalidTokenSize},
+		{Name: "large payload", Items: [][]byte{item(itemToken, token), item(itemPayload, make([]byte, MaxLegacyPayloadSize+1)), item(itemID, id)}, Status: InvalidPayloadSize},
+		{Name: "bad priority", Items: [][]byte{item(itemToken, token), item(itemPayload, []byte(`{}`)), item(itemID, id), item(itemPriority, []byte{3})}, Status: ProcessingError},
+	}
+	for _, v := range tab {
+		frame := bytes.Join(v.Items, nil)
+		b := append([]byte{CommandNotification, 0, 0, byte(len(frame) >> 8), byte(len(frame))}, frame...)
+		_, err := ReadNotification(bytes.NewReader(b))
+		e, ok := err.(*ProtocolError)
+		if !ok {
+			t.Errorf("%s: ReadNotification = %v; want *ProtocolError", v.Name, err)
+			continue
+		}
+		if e.Cmd != CommandErrorResponse || e.Status != v.Status || e.ID != 7 {
+			t.Errorf("%s: ReadNotification = %+v; want status %d with id 7", v.Name, e, v.Status)
+		}
+	}
+
+	if _, err := ReadNotification(bytes.NewReader([]byte{1, 0})); err == nil {
+		t.Errorf("ReadNotification(command 1) = nil; want an error")
+	}
+	if _, err := ReadNotification(bytes.NewReader([]byte{CommandNotification, 0, 0, 0, 9, 1})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadNotification(truncated) = %v; want ErrUnexpectedEOF", err)
+	}
+}
+
+func item(id uint8, data []byte) []byte {
+	return appendItem(nil, id, data)
+}
+
+func TestErrorResponse(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteErrorResponse(&buf, &ProtocolError{Status: InvalidToken, ID: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "08080000002a"; hex.EncodeToString(buf.Bytes()) != want {
+		t.Errorf("WriteErrorResponse = %x; want %s", buf.Bytes(), want)
+	}
+	e, err := ReadErrorResponse(&buf)
+	if err != nil {
+		t.Fatalf("ReadErrorResponse: %v", err)
+	}
+	if e.Cmd != CommandErrorResponse || e.Status != InvalidToken || e.ID != 42 || !e.InvalidToken() {
+		t.Errorf("ReadErrorResponse = %+v", e)
+	}
+}
+
+func TestFBResponse(t *testing.T) {
+	resp := &FBResponse{Body: []*Feedback{
+		{Timestamp: 1600000000, Token: bytes.Repeat([]byte{1}, LegacyTokenSize)},
+		{Timestamp: 1600000001, Token: bytes.Repeat([]byte{2}, LegacyTokenSize)},
+	}}
+	var buf bytes.Buffer
+	if err := WriteFBResponse(&buf, resp); err != nil {
+		t.Fatal(err)
+	}
+	if n := buf.Len(); n != 2*(6+LegacyTokenSize) {
+		t.Errorf("len = %d; want %d", n, 2*(6+LegacyTokenSize))
+	}
+	got, err := ReadFBResponse(&buf)
+	if err != nil {
+		t.Fatalf("ReadFBResponse: %v", err)
+	}
+	if !reflect.DeepEqual(got, resp) {
+		t.Errorf("ReadFBResponse = %+v; want %+v", got, resp)
+	}
+
+	if _, err := ReadFBResponse(bytes.NewReader([]byte{0, 0, 0, 1, 0, 32, 1})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadFBResponse(truncated) = %v; want ErrUnexpectedEOF", err)
+	}
+}
@@ -0,0 +1,157 @@
+package apns
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	// Appleのプッシュ証明書に含まれる拡張のOID
+	oidDevelopment = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 3, 1}
+	oidProduction  = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 3, 2}
+	oidTopics      = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 6, 3, 6}
+
+	// SubjectのUID(Bundle IDが入る)
+	oidUserID = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}
+
+	errMissingCertificate = errors.New("apns: credential has no certificate")
+)
+
+// LoadPKCS12はAppleから取得した.p12ファイルの内容をpasswordで復号してCredentialを返す。
+func LoadPKCS12(data []byte, password string) (*Credential, error) {
+	key, cert, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	for _, c := range caCerts {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return &Credential{
+		KeyPEMBlock:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		CertPEMBlock: certPEM,
+	}, nil
+}
+
+// CertificateInfoはAPNs証明書の内容をあらわす。
+type CertificateInfo struct {
+	// SubjectのCommonName(例: "Apple Push Services: com.example.app")
+	Subject string
+	// SubjectのUIDに入っているBundle ID
+	BundleID string
+
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// 開発環境(api.sandbox.push.apple.com)で使える
+	Development bool
+	// 本番環境(api.push.apple.com)で使える
+	Production bool
+
+	// 送信できるトピック(複数トピック証明書の場合のみ)
+	Topics []string
+}
+
+// ExpiresWithinはnowからdの間に証明書の有効期限が切れる場合にtrueを返す。
+// すでに期限切れの場合もtrueを返す。
+func (info *CertificateInfo) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return !now.Add(d).Before(info.NotAfter)
+}
+
+// AllowsTopicは証明書がtopicへの送信に使える場合にtrueを返す。
+func (info *CertificateInfo) AllowsTopic(topic string) bool {
+	if len(info.Topics) == 0 {
+		return topic == "" || topic == info.BundleID
+	}
+	for _, s := range info.Topics {
+		if s == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectCertificateはcredの証明書を調べて、その内容を返す。
+func InspectCertificate(cred *Credential) (*CertificateInfo, error) {
+	cert, err := parseCertificate(cred)
+	if err != nil {
+		return nil, err
+	}
+	info := &CertificateInfo{
+		Subject:   cert.Subject.CommonName,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}
+	for _, v := range cert.Subject.Names {
+		if v.Type.Equal(oidUserID) {
+			info.BundleID, _ = v.Value.(string)
+		}
+	}
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidDevelopment):
+			info.Development = true
+		case ext.Id.Equal(oidProduction):
+			info.Production = true
+		case ext.Id.Equal(oidTopics):
+			topics, err := parseTopics(ext.Value)
+			if err != nil {
+				return nil, err
+			}
+			info.Topics = topics
+		}
+	}
+	if !info.Development && !info.Production {
+		// 古い証明書は拡張を持たないのでCommonNameで判断する
+		info.Development = strings.Contains(info.Subject, "Development")
+		info.Production = !info.Development
+	}
+	return info, nil
+}
+
+func parseCertificate(cred *Credential) (*x509.Certificate, error) {
+	block, _ := pem.Decode(cred.CertPEMBlock)
+	if block == nil {
+		return nil, errMissingCertificate
+	}
+	if len(cred.KeyPEMBlock) > 0 {
+		if _, err := tls.X509KeyPair(cred.CertPEMBlock, cred.KeyPEMBlock); err != nil {
+			return nil, err
+		}
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// parseTopicsは複数トピック拡張の値からトピックを取り出す。
+// 値はトピックとその種類(SEQUENCE)が交互に並ぶSEQUENCEになっている。
+func parseTopics(b []byte) ([]string, error) {
+	var seq asn1.RawValue
+	if _, err := asn1.Unmarshal(b, &seq); err != nil {
+		return nil, err
+	}
+	var topics []string
+	rest := seq.Bytes
+	for len(rest) > 0 {
+		var v asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &v)
+		if err != nil {
+			return nil, err
+		}
+		if v.Class == asn1.ClassUniversal && v.Tag == asn1.TagUTF8String {
+			topics = append(topics, string(v.Bytes))
+		}
+	}
+	return topics, nil
+}
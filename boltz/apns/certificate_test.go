@@ -0,0 +1,133 @@
+package apns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// newCertCredentialはSubjectのCommonNameとUIDを持つ自己署名証明書のCredentialを返す。
+func newCertCredential(t *testing.T, commonName, uid string) *Credential {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: commonName,
+			ExtraNames: []pkix.AttributeTypeAndValue{{Type: oidUserID, Value: uid}},
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Credential{CertPEMBlock: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func marshalTopics(t *testing.T, topics map[string]string) []byte {
+	t.Helper()
+	var b []byte
+	for topic, kind := range topics {
+		v, err := asn1.MarshalWithParams(topic, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = append(b, v...)
+		k, err := asn1.MarshalWithParams(kind, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seq, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: k})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = append(b, seq...)
+	}
+	v, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLoadPKCS12(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "Apple Development IOS Push Services: com.example.app",
+			ExtraNames: []pkix.AttributeTypeAndValue{
+				{Type: oidUserID, Value: "com.example.app"},
+			},
+		},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  notAfter,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidDevelopment, Value: []byte{0x05, 0x00}},
+			{Id: oidTopics, Value: marshalTopics(t, map[string]string{"com.example.app": "app"})},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := pkcs12.Modern.Encode(key, cert, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPKCS12(data, "wrong"); err == nil {
+		t.Errorf("LoadPKCS12 with wrong password = nil; want an error")
+	}
+	cred, err := LoadPKCS12(data, "secret")
+	if err != nil {
+		t.Fatalf("LoadPKCS12: %v", err)
+	}
+	info, err := InspectCertificate(cred)
+	if err != nil {
+		t.Fatalf("InspectCertificate: %v", err)
+	}
+	want := &CertificateInfo{
+		Subject:     tmpl.Subject.CommonName,
+		BundleID:    "com.example.app",
+		NotBefore:   cert.NotBefore,
+		NotAfter:    notAfter,
+		Development: true,
+		Topics:      []string{"com.example.app"},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("InspectCertificate = %+v; want %+v", info, want)
+	}
+	if info.ExpiresWithin(time.Now(), 7*24*time.Hour) {
+		t.Errorf("ExpiresWithin(7 days) = true; want false")
+	}
+	if !info.ExpiresWithin(time.Now(), 31*24*time.Hour) {
+		t.Errorf("ExpiresWithin(31 days) = false; want true")
+	}
+	if !info.AllowsTopic("com.example.app") || info.AllowsTopic("com.example.other") {
+		t.Errorf("AllowsTopic does not match Topics %v", info.Topics)
+	}
+}
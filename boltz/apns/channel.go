@@ -0,0 +1,66 @@
+package apns
+
+// ブロードキャストチャネル管理APIのアドレス
+const (
+	ChannelAddr        = "https://api-manage-broadcast.push.apple.com:2196"
+	SandboxChannelAddr = "https://api-manage-broadcast.sandbox.push.apple.com:2195"
+)
+
+// MessageStoragePolicyはチャネルに送信した通知をAPNsが保存するかどうかをあらわす。
+type MessageStoragePolicy int
+
+const (
+	// 通知を保存しない
+	NoMessageStored MessageStoragePolicy = 0
+	// 最新の通知をひとつだけ保存する
+	MostRecentMessageStored MessageStoragePolicy = 1
+)
+
+// ChannelPushTypeLiveActivityはチャネルで配信できるプッシュタイプ。
+const ChannelPushTypeLiveActivity = "LiveActivity"
+
+// ChannelOperationはChannelRequestで行う操作をあらわす。
+type ChannelOperation int
+
+const (
+	ListChannels ChannelOperation = iota
+	CreateChannel
+	ReadChannel
+	DeleteChannel
+)
+
+// Channelはブロードキャストプッシュのチャネルをあらわす。
+type Channel struct {
+	// apns-channel-id (作成時はAPNsが割り当てる)
+	ID string
+	// 保存ポリシー
+	MessageStoragePolicy MessageStoragePolicy
+	// ChannelPushTypeLiveActivity
+	PushType string
+}
+
+// ChannelRequestはマスタからスレーブに対してリクエストするチャネル操作をあらわす。
+type ChannelRequest struct {
+	// チャネル管理APIのアドレス(ChannelAddrまたはSandboxChannelAddr)
+	Addr string
+	// APNs接続時の資格情報
+	Credential *Credential
+	// チャネルを所有するアプリのBundle ID
+	BundleID string
+	// 操作
+	Operation ChannelOperation
+	// 作成・参照・削除するチャネル(ListChannelsの場合は不要)
+	Channel *Channel
+}
+
+// ChannelResponseはチャネル操作に対するスレーブからの応答をあらわす。
+// 必ず、ErrorString、Detailはどちらか1つだけセットされるか、どちらもセットされない。
+type ChannelResponse struct {
+	// 作成・参照したチャネル、またはチャネル一覧
+	Channels []*Channel
+
+	// APNsとは関係のない場所で発生したエラー(例えば"no such host")
+	ErrorString string
+	// APNsプロトコルにおけるエラーの場合にセット
+	Detail *ProtocolError
+}
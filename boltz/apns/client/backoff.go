@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+const (
+	// TooManyRequestsを受けたトークンの最初の待機時間
+	minBackoff = time.Second
+	// 待機時間の上限(TooManyRequestsが続く場合は倍々に延ばす)
+	maxBackoff = 5 * time.Minute
+
+	// 待機状態の掃除を始めるエントリ数
+	backoffSweepSize = 1024
+)
+
+// backoffTrackerはTooManyRequestsを返したデバイストークンごとの待機状態を管理する。
+// 同じトークンへの送信はリクエストをまたいで抑制するため、Clientがひとつ保持する。
+type backoffTracker struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]*backoffState
+}
+
+type backoffState struct {
+	until time.Time     // この時刻まで送信しない
+	wait  time.Duration // 直前の待機時間
+}
+
+func newBackoffTracker() *backoffTracker {
+	return &backoffTracker{
+		now:    time.Now,
+		tokens: make(map[string]*backoffState),
+	}
+}
+
+// Untilはtokenへの送信を再開してよい時刻を返す。
+// 待機中でなければゼロ値を返す。
+func (b *backoffTracker) Until(token []byte) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.tokens[string(token)]
+	if !ok || !s.until.After(b.now()) {
+		return time.Time{}
+	}
+	return s.until
+}
+
+// Failはtokenへの送信がTooManyRequestsで拒否されたことを記録して、
+// 送信を再開してよい時刻を返す。
+func (b *backoffTracker) Fail(token []byte) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if len(b.tokens) >= backoffSweepSize {
+		b.sweep(now)
+	}
+	s, ok := b.tokens[string(token)]
+	switch {
+	case !ok:
+		s = &backoffState{wait: minBackoff}
+		b.tokens[string(token)] = s
+	case s.until.After(now):
+		// 待機中に送信済みだったメッセージの失敗なので延長しない
+		return s.until
+	default:
+		s.wait *= 2
+		if s.wait > maxBackoff {
+			s.wait = maxBackoff
+		}
+	}
+	s.until = now.Add(s.wait)
+	return s.until
+}
+
+// Resetはtokenへの送信が成功したので待機状態を破棄する。
+func (b *backoffTracker) Reset(token []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tokens, string(token))
+}
+
+// sweepは十分前に待機を終えたエントリを破棄する。
+func (b *backoffTracker) sweep(now time.Time) {
+	for k, s := range b.tokens {
+		if now.Sub(s.until) > maxBackoff {
+			delete(b.tokens, k)
+		}
+	}
+}
+
+func (c *Client) tracker() *backoffTracker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.backoff == nil {
+		c.backoff = newBackoffTracker()
+	}
+	return c.backoff
+}
+
+// waitBackoffはmのトークンが待機中であれば、maxWait以内に解除される場合に限り解除まで待つ。
+// 待たずに送信を見送るべき場合はDeferredMessageを返す。
+func waitBackoff(ctx context.Context, b *backoffTracker, m *apns.Message, maxWait time.Duration) (*apns.DeferredMessage, error) {
+	if m.ChannelID != "" {
+		return nil, nil
+	}
+	until := b.Until(m.Token)
+	if until.IsZero() {
+		return nil, nil
+	}
+	d := until.Sub(b.now())
+	if d > maxWait {
+		return &apns.DeferredMessage{RetryAfter: until, Message: m}, nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// recordBackoffはmの送信結果をbへ記録する。
+// TooManyRequestsで失敗した場合は、fの代わりに返すDeferredMessageを返す。
+func recordBackoff(b *backoffTracker, m *apns.Message, f *apns.FailedMessage) *apns.DeferredMessage {
+	if m.ChannelID != "" {
+		return nil
+	}
+	if f == nil {
+		b.Reset(m.Token)
+		return nil
+	}
+	if f.Detail == nil || f.Detail.ReasonCode() != apns.ReasonTooManyRequests {
+		return nil
+	}
+	return &apns.DeferredMessage{
+		RetryAfter: b.Fail(m.Token),
+		Detail:     f.Detail,
+		Message:    m,
+	}
+}
+
+// coalesceは同じトークンとCollapseIDを持つ後続のメッセージが
+// 送信または見送りされたメッセージのCoalescedをセットする。
+// 後続のメッセージが端末の表示を置き換えるので、見送ったメッセージを再送する必要はない。
+func coalesce(messages []*apns.Message, deferred []*apns.DeferredMessage, receipts []*apns.Receipt) {
+	type key struct {
+		Token      string
+		CollapseID string
+	}
+	seen := make(map[key]bool)
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.CollapseID == "" || m.ChannelID != "" {
+			continue
+		}
+		k := key{Token: hex.EncodeToString(m.Token), CollapseID: m.CollapseID}
+		if deferred[i] != nil && seen[k] {
+			deferred[i].Coalesced = true
+		}
+		if deferred[i] != nil || receipts[i] != nil {
+			seen[k] = true
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/apns/apnstest"
+)
+
+func TestBackoffTracker(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	b := newBackoffTracker()
+	b.now = func() time.Time { return now }
+	token := []byte{1}
+
+	if until := b.Until(token); !until.IsZero() {
+		t.Errorf("Until = %v; want zero", until)
+	}
+	if until := b.Fail(token); !until.Equal(now.Add(minBackoff)) {
+		t.Errorf("Fail = %v; want %v", until, now.Add(minBackoff))
+	}
+	// 待機中の失敗は延長しない
+	if until := b.Fail(token); !until.Equal(now.Add(minBackoff)) {
+		t.Errorf("Fail while waiting = %v; want %v", until, now.Add(minBackoff))
+	}
+	now = now.Add(minBackoff)
+	if until := b.Until(token); !until.IsZero() {
+		t.Errorf("Until after wait = %v; want zero", until)
+	}
+	if until := b.Fail(token); !until.Equal(now.Add(2 * minBackoff)) {
+		t.Errorf("second Fail = %v; want %v", until, now.Add(2*minBackoff))
+	}
+	for i := 0; i < 20; i++ {
+		now = b.Until(token).Add(time.Millisecond)
+		b.Fail(token)
+	}
+	if d := b.Until(token).Sub(now); d != maxBackoff {
+		t.Errorf("wait = %v; want %v", d, maxBackoff)
+	}
+	b.Reset(token)
+	if until := b.Until(token); !until.IsZero() {
+		t.Errorf("Until after Reset = %v; want zero", until)
+	}
+}
+
+func TestDoBackoff(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	cred := &apns.Credential{
+		Issuer:             "TEAMID",
+		KeyID:              "KEYID",
+		PrivateKey:         newTestKey(t),
+		InsecureSkipVerify: true,
+	}
+	if err := s.AddCredential(cred); err != nil {
+		t.Fatal(err)
+	}
+	s.SetResponse("01", apnstest.TooManyRequests())
+	newMessage := func(token byte, collapseID string) *apns.Message {
+		return &apns.Message{Token: []byte{token}, Topic: "com.example.app", CollapseID: collapseID, Payload: []byte(`{}`)}
+	}
+
+	var c Client
+	req := &apns.Request{
+		Addr:       s.URL,
+		Credential: cred,
+		Messages:   []*apns.Message{newMessage(1, "score"), newMessage(1, "score"), newMessage(2, "")},
+	}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 0 || len(resp.Receipts) != 1 {
+		t.Fatalf("Do = %d failures, %d receipts; want 0, 1", len(resp.FailedMessages), len(resp.Receipts))
+	}
+	if len(resp.DeferredMessages) != 2 {
+		t.Fatalf("len(DeferredMessages) = %d; want 2", len(resp.DeferredMessages))
+	}
+	if d := resp.DeferredMessages[0]; d.Message != req.Messages[0] || !d.Coalesced {
+		t.Errorf("DeferredMessages[0] = %+v; want coalesced first message", d)
+	}
+	if d := resp.DeferredMessages[1]; d.Message != req.Messages[1] || d.Coalesced || d.RetryAfter.IsZero() {
+		t.Errorf("DeferredMessages[1] = %+v; want second message with RetryAfter", d)
+	}
+
+	// 待機中のトークンへは送信しない
+	n := len(s.Notifications())
+	req.Messages = []*apns.Message{newMessage(1, "")}
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.DeferredMessages) != 1 || resp.DeferredMessages[0].Detail != nil {
+		t.Errorf("DeferredMessages = %+v; want deferred without sending", resp.DeferredMessages)
+	}
+	if m := len(s.Notifications()); m != n {
+		t.Errorf("len(Notifications) = %d; want %d", m, n)
+	}
+
+	// MaxBackoffWait以内に解除されるなら待って送信する
+	s.SetResponse("01", nil)
+	req.MaxBackoffWait = 2 * minBackoff
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.Receipts) != 1 || len(resp.DeferredMessages) != 0 {
+		t.Errorf("Do = %d receipts, %d deferred; want 1, 0", len(resp.Receipts), len(resp.DeferredMessages))
+	}
+	if until := c.tracker().Until([]byte{1}); !until.IsZero() {
+		t.Errorf("Until after success = %v; want zero", until)
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+// channelBodyはチャネル管理APIのリクエスト・レスポンスのボディをあらわす。
+type channelBody struct {
+	MessageStoragePolicy apns.MessageStoragePolicy `json:"message-storage-policy"`
+	PushType             string                    `json:"push-type"`
+}
+
+// channelListBodyはチャネル一覧のレスポンスボディをあらわす。
+type channelListBody struct {
+	Channels []string `json:"channels"`
+}
+
+// ManageChannelはreqのチャネル操作をAPNsのチャネル管理APIで実行する。
+// APNsが返したエラーはChannelResponseにセットし、
+// リクエスト自体を処理できない場合のみエラーを返す。
+func (c *Client) ManageChannel(ctx context.Context, req *apns.ChannelRequest) (*apns.ChannelResponse, error) {
+	if apns.IsLegacyAddr(req.Addr) {
+		return nil, errLegacyAddr
+	}
+	if req.Credential == nil {
+		return nil, errMissingCredential
+	}
+	if req.Operation != apns.ListChannels && req.Channel == nil {
+		return nil, fmt.Errorf("apns: channel is required for operation %d", req.Operation)
+	}
+	s, err := c.sender(req.Addr, apns.EnvironmentOf(req.Addr), req.Credential, connPolicy(nil))
+	if err != nil {
+		return nil, err
+	}
+	resp, e, err := s.do(ctx, func(token string) (*http.Request, error) {
+		r, err := s.newChannelRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		setToken(r, token)
+		return r.WithContext(ctx), nil
+	})
+	switch {
+	case err != nil:
+		return &apns.ChannelResponse{ErrorString: err.Error()}, nil
+	case e != nil:
+		return &apns.ChannelResponse{Detail: e}, nil
+	}
+	channels, err := parseChannels(req, resp)
+	if err != nil {
+		return &apns.ChannelResponse{ErrorString: err.Error()}, nil
+	}
+	return &apns.ChannelResponse{Channels: channels}, nil
+}
+
+func (s *sender) newChannelRequest(req *apns.ChannelRequest) (*http.Request, error) {
+	base := s.baseURL + "/1/apps/" + url.PathEscape(req.BundleID)
+	var (
+		r   *http.Request
+		err error
+	)
+	switch req.Operation {
+	case apns.ListChannels:
+		r, err = http.NewRequest(http.MethodGet, base+"/all-channels", nil)
+	case apns.CreateChannel:
+		pushType := req.Channel.PushType
+		if pushType == "" {
+			pushType = apns.ChannelPushTypeLiveActivity
+		}
+		b, err := json.Marshal(&channelBody{
+			MessageStoragePolicy: req.Channel.MessageStoragePolicy,
+			PushType:             pushType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		r, err = http.NewRequest(http.MethodPost, base+"/channels", bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/json")
+	case apns.ReadChannel:
+		r, err = http.NewRequest(http.MethodGet, base+"/channels", nil)
+	case apns.DeleteChannel:
+		r, err = http.NewRequest(http.MethodDelete, base+"/channels", nil)
+	default:
+		return nil, fmt.Errorf("apns: unknown channel operation %d", req.Operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Operation != apns.ListChannels && req.Operation != apns.CreateChannel {
+		r.Header.Set("apns-channel-id", req.Channel.ID)
+	}
+	return r, nil
+}
+
+func parseChannels(req *apns.ChannelRequest, resp *response) ([]*apns.Channel, error) {
+	switch req.Operation {
+	case apns.ListChannels:
+		var v channelListBody
+		if err := json.Unmarshal(resp.Body, &v); err != nil {
+			return nil, err
+		}
+		channels := make([]*apns.Channel, len(v.Channels))
+		for i, id := range v.Channels {
+			channels[i] = &apns.Channel{ID: id}
+		}
+		return channels, nil
+	case apns.CreateChannel:
+		ch := *req.Channel
+		ch.ID = resp.Header.Get("apns-channel-id")
+		if ch.PushType == "" {
+			ch.PushType = apns.ChannelPushTypeLiveActivity
+		}
+		return []*apns.Channel{&ch}, nil
+	case apns.ReadChannel:
+		var v channelBody
+		if err := json.Unmarshal(resp.Body, &v); err != nil {
+			return nil, err
+		}
+		return []*apns.Channel{{
+			ID:                   req.Channel.ID,
+			MessageStoragePolicy: v.MessageStoragePolicy,
+			PushType:             v.PushType,
+		}}, nil
+	}
+	return []*apns.Channel{}, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+func TestManageChannel(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("apns-channel-id")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /1/apps/com.example.app/all-channels":
+			w.Write([]byte(`{"channels":["c1","c2"]}`))
+		case "POST /1/apps/com.example.app/channels":
+			var v channelBody
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				t.Errorf("Decode: %v", err)
+			}
+			if v.PushType != apns.ChannelPushTypeLiveActivity {
+				t.Errorf("push-type = %q; want %q", v.PushType, apns.ChannelPushTypeLiveActivity)
+			}
+			w.Header().Set("apns-channel-id", "c3")
+			w.WriteHeader(http.StatusCreated)
+		case "GET /1/apps/com.example.app/channels":
+			if id != "c1" {
+				w.Header().Set("apns-request-id", "req-1")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"reason":"ChannelNotRegistered"}`))
+				return
+			}
+			w.Write([]byte(`{"message-storage-policy":1,"push-type":"LiveActivity"}`))
+		case "DELETE /1/apps/com.example.app/channels":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	c := &Client{Transport: s.Client().Transport}
+	newRequest := func(op apns.ChannelOperation, ch *apns.Channel) *apns.ChannelRequest {
+		return &apns.ChannelRequest{
+			Addr:       s.URL,
+			Credential: &apns.Credential{},
+			BundleID:   "com.example.app",
+			Operation:  op,
+			Channel:    ch,
+		}
+	}
+	ctx := context.Background()
+
+	resp, err := c.ManageChannel(ctx, newRequest(apns.ListChannels, nil))
+	if err != nil || len(resp.Channels) != 2 || resp.Channels[1].ID != "c2" {
+		t.Errorf("ListChannels = %+v, %v; want 2 channels", resp, err)
+	}
+
+	resp, err = c.ManageChannel(ctx, newRequest(apns.CreateChannel, &apns.Channel{
+		MessageStoragePolicy: apns.MostRecentMessageStored,
+	}))
+	if err != nil || len(resp.Channels) != 1 || resp.Channels[0].ID != "c3" {
+		t.Errorf("CreateChannel = %+v, %v; want channel c3", resp, err)
+	}
+
+	resp, err = c.ManageChannel(ctx, newRequest(apns.ReadChannel, &apns.Channel{ID: "c1"}))
+	if err != nil || len(resp.Channels) != 1 || resp.Channels[0].MessageStoragePolicy != apns.MostRecentMessageStored {
+		t.Errorf("ReadChannel = %+v, %v; want policy 1", resp, err)
+	}
+
+	resp, err = c.ManageChannel(ctx, newRequest(apns.ReadChannel, &apns.Channel{ID: "unknown"}))
+	if err != nil || resp.Detail == nil || resp.Detail.StatusCode != http.StatusNotFound {
+		t.Fatalf("ReadChannel(unknown) = %+v, %v; want 404", resp, err)
+	}
+	if e := resp.Detail; e.Reason != "ChannelNotRegistered" || e.RequestID != "req-1" {
+		t.Errorf("ReadChannel(unknown) = %+v; want ChannelNotRegistered with apns-request-id", e)
+	}
+
+	resp, err = c.ManageChannel(ctx, newRequest(apns.DeleteChannel, &apns.Channel{ID: "c1"}))
+	if err != nil || resp.Detail != nil || resp.ErrorString != "" {
+		t.Errorf("DeleteChannel = %+v, %v; want success", resp, err)
+	}
+}
+
+func TestDoBroadcast(t *testing.T) {
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/4/broadcasts/apps/com.example.app" {
+			t.Errorf("Path = %q; want broadcast path", r.URL.Path)
+		}
+		if v := r.Header.Get("apns-channel-id"); v != "c1" {
+			t.Errorf("apns-channel-id = %q; want %q", v, "c1")
+		}
+	})
+	req := &apns.Request{
+		Addr:       s.URL,
+		Credential: &apns.Credential{},
+		Messages: []*apns.Message{
+			{
+				ChannelID: "c1",
+				Topic:     apns.Topic("com.example.app", apns.PushTypeLiveActivity),
+				PushType:  apns.PushTypeLiveActivity,
+				Payload:   []byte(`{}`),
+			},
+		},
+	}
+	c := &Client{Transport: s.Client().Transport}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil || len(resp.FailedMessages) != 0 {
+		t.Errorf("Do = %+v, %v; want success", resp, err)
+	}
+}
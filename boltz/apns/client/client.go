@@ -0,0 +1,473 @@
+// Package client implements APNs provider API client that executes apns.Request.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/internal/throttle"
+)
+
+// レスポンスボディとして読み込む最大バイト数
+const maxResponseBody = 64 * 1024
+
+var (
+	errLegacyAddr        = errors.New("apns: binary protocol address is not supported")
+	errMissingCredential = errors.New("apns: missing credential")
+)
+
+// ClientはAPNs HTTP/2 APIへ通知を送信するクライアントをあらわす。
+// ゼロ値のClientはRequest.Credentialから接続を作成する。
+// 作成した接続はリクエスト間で使い回すので、Clientは複数のリクエストで共有すること。
+type Client struct {
+	// Transportがnilでなければ、Credentialから作成する代わりに使う。(主にテスト用)
+	Transport http.RoundTripper
+
+	mu      sync.Mutex
+	tokens  map[tokenKey]*apns.TokenSource
+	senders map[senderKey]*sender
+	backoff *backoffTracker
+}
+
+// tokenKeyはTokenSourceをリクエスト間で共有するためのキーをあらわす。
+type tokenKey struct {
+	Issuer     string
+	KeyID      string
+	PrivateKey string
+}
+
+// senderKeyはsenderをリクエスト間で共有するためのキーをあらわす。
+type senderKey struct {
+	Addr               string
+	Env                apns.Environment
+	KeyPEMBlock        string
+	CertPEMBlock       string
+	InsecureSkipVerify bool
+	Token              tokenKey
+	Policy             apns.ConnectionPolicy
+}
+
+// Doはreqに含まれるすべてのメッセージをAPNsへ送信する。
+// 個々のメッセージの失敗はResponse.FailedMessagesで返し、
+// リクエスト自体を処理できない場合のみエラーを返す。
+func (c *Client) Do(ctx context.Context, req *apns.Request) (*apns.Response, error) {
+	if apns.IsLegacyAddr(req.Addr) {
+		return nil, errLegacyAddr
+	}
+	if req.Credential == nil {
+		return nil, errMissingCredential
+	}
+	policy := connPolicy(req.Connection)
+	s, err := c.sender(req.Addr, apns.EnvironmentOf(req.Addr), req.Credential, policy)
+	if err != nil {
+		return nil, err
+	}
+	// 開発環境への再送が必要になるまで、開発環境への接続は作らない
+	var sandbox func() (*sender, error)
+	if req.SandboxFallback && s.env != apns.EnvironmentDevelopment {
+		addr := req.SandboxAddr
+		if addr == "" {
+			addr = apns.DevelopmentAddr
+		}
+		sandbox = func() (*sender, error) {
+			return c.sender(addr, apns.EnvironmentDevelopment, req.Credential, policy)
+		}
+	}
+
+	// 呼び出し元のメッセージを変更しないように、推測はコピーに対して行って、コピーを送信する
+	messages := req.Messages
+	warnings := []*apns.PushTypeWarning{}
+	if req.InferPushType {
+		messages = make([]*apns.Message, len(req.Messages))
+		for i, m := range req.Messages {
+			v := *m
+			if w := v.InferPushType(); w != nil {
+				w.Message = m
+				warnings = append(warnings, w)
+			}
+			messages[i] = &v
+		}
+	}
+
+	failures := make([]*apns.FailedMessage, len(req.Messages))
+	receipts := make([]*apns.Receipt, len(req.Messages))
+	deferred := make([]*apns.DeferredMessage, len(req.Messages))
+	backoff := c.tracker()
+	p := throttle.NewPacer(req.BandWidth)
+	defer p.Stop()
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	n := int(policy.Connections * policy.MaxConcurrentStreams)
+	if n > len(req.Messages) {
+		n = len(req.Messages)
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				m := messages[i]
+				d, err := waitBackoff(ctx, backoff, m, req.MaxBackoffWait)
+				switch {
+				case err != nil:
+					failures[i] = &apns.FailedMessage{ErrorString: err.Error(), Message: m}
+					continue
+				case d != nil:
+					deferred[i] = d
+					continue
+				}
+				receipts[i], failures[i] = sendWithFallback(ctx, s, sandbox, m)
+				if d := recordBackoff(backoff, m, failures[i]); d != nil {
+					deferred[i], failures[i] = d, nil
+				}
+			}
+		}()
+	}
+	for i := range req.Messages {
+		if err := p.Wait(ctx); err != nil {
+			failures[i] = &apns.FailedMessage{
+				ErrorString: err.Error(),
+				Message:     req.Messages[i],
+			}
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	coalesce(req.Messages, deferred, receipts)
+
+	resp := &apns.Response{
+		FailedMessages:   []*apns.FailedMessage{},
+		Receipts:         []*apns.Receipt{},
+		DeferredMessages: []*apns.DeferredMessage{},
+		Warnings:         warnings,
+	}
+	// 結果には送信したコピーではなく、呼び出し元のメッセージをセットする
+	for i, f := range failures {
+		if f != nil {
+			f.Message = req.Messages[i]
+			resp.FailedMessages = append(resp.FailedMessages, f)
+		}
+		if receipts[i] != nil {
+			receipts[i].Message = req.Messages[i]
+			resp.Receipts = append(resp.Receipts, receipts[i])
+		}
+		if deferred[i] != nil {
+			deferred[i].Message = req.Messages[i]
+			resp.DeferredMessages = append(resp.DeferredMessages, deferred[i])
+		}
+	}
+	return resp, nil
+}
+
+// senderはひとつの接続先への接続状態をあらわす。
+// 同じ接続先・資格情報・ポリシーのリクエストで共有する。
+type sender struct {
+	conns   []*conn
+	next    uint32 // 次に使う接続(atomic)
+	policy  apns.ConnectionPolicy
+	baseURL string
+	env     apns.Environment
+	tokens  *apns.TokenSource // JWT認証の場合のみ
+}
+
+// senderはaddrへcredで接続するsenderを返す。
+// 同じ接続先・資格情報・ポリシーのsenderはリクエスト間で使い回して、HTTP/2接続を開いたままにする。
+func (c *Client) sender(addr string, env apns.Environment, cred *apns.Credential, policy apns.ConnectionPolicy) (*sender, error) {
+	key := senderKey{
+		Addr:               addr,
+		Env:                env,
+		KeyPEMBlock:        string(cred.KeyPEMBlock),
+		CertPEMBlock:       string(cred.CertPEMBlock),
+		InsecureSkipVerify: cred.InsecureSkipVerify,
+		Token: tokenKey{
+			Issuer:     cred.Issuer,
+			KeyID:      cred.KeyID,
+			PrivateKey: string(cred.PrivateKey),
+		},
+		Policy: policy,
+	}
+	c.mu.Lock()
+	s, ok := c.senders[key]
+	c.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	// tokenSourceもc.muを使うので、ロックせずに作成する
+	s, err := c.newSender(addr, env, cred, policy)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.senders[key]; ok {
+		// 並行して作成されたsenderがあればそちらを使う
+		s.closeIdleConnections()
+		return v, nil
+	}
+	if c.senders == nil {
+		c.senders = make(map[senderKey]*sender)
+	}
+	c.senders[key] = s
+	return s, nil
+}
+
+func (c *Client) newSender(addr string, env apns.Environment, cred *apns.Credential, policy apns.ConnectionPolicy) (*sender, error) {
+	s := &sender{
+		conns:   make([]*conn, policy.Connections),
+		policy:  policy,
+		baseURL: strings.TrimSuffix(addr, "/"),
+		env:     env,
+	}
+	for i := range s.conns {
+		t := c.Transport
+		if t == nil {
+			tr, err := newTransport(cred, policy)
+			if err != nil {
+				return nil, err
+			}
+			t = tr
+		}
+		s.conns[i] = newConn(t, policy)
+	}
+	if cred.HasProviderToken() {
+		tokens, err := c.tokenSource(cred)
+		if err != nil {
+			return nil, err
+		}
+		s.tokens = tokens
+	}
+	return s, nil
+}
+
+// CloseIdleConnectionsはリクエスト間で使い回している接続のうち、使用中でないものを閉じる。
+// 閉じた接続は次のリクエストで開き直す。
+func (c *Client) CloseIdleConnections() {
+	c.mu.Lock()
+	senders := make([]*sender, 0, len(c.senders))
+	for _, s := range c.senders {
+		senders = append(senders, s)
+	}
+	c.mu.Unlock()
+	for _, s := range senders {
+		s.closeIdleConnections()
+	}
+}
+
+// tokenSourceはcredに対応するTokenSourceを返す。
+// APNsはトークンの頻繁な更新を拒否するため、同じ鍵のTokenSourceはリクエスト間で使い回す。
+func (c *Client) tokenSource(cred *apns.Credential) (*apns.TokenSource, error) {
+	key := tokenKey{
+		Issuer:     cred.Issuer,
+		KeyID:      cred.KeyID,
+		PrivateKey: string(cred.PrivateKey),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.tokens[key]; ok {
+		return s, nil
+	}
+	s, err := apns.NewTokenSource(cred)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokens == nil {
+		c.tokens = make(map[tokenKey]*apns.TokenSource)
+	}
+	c.tokens[key] = s
+	return s, nil
+}
+
+func tlsConfig(cred *apns.Credential) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: cred.InsecureSkipVerify,
+	}
+	if len(cred.CertPEMBlock) > 0 || len(cred.KeyPEMBlock) > 0 {
+		cert, err := tls.X509KeyPair(cred.CertPEMBlock, cred.KeyPEMBlock)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// errorBodyはAPNsが返すエラーレスポンスのボディをあらわす。
+type errorBody struct {
+	Reason    apns.Reason `json:"reason"`
+	Timestamp int64       `json:"timestamp"` // Unix time in milliseconds
+}
+
+// sendはmをAPNsへ送信する。
+// 成功した場合はReceiptを、失敗した場合はFailedMessageを返す。
+func (s *sender) send(ctx context.Context, m *apns.Message) (*apns.Receipt, *apns.FailedMessage) {
+	id := m.APNsID
+	if id == "" {
+		id = apns.NewAPNsID()
+	}
+	resp, e, err := s.do(ctx, func(token string) (*http.Request, error) {
+		r, err := s.newRequest(ctx, m, token)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("apns-id", id)
+		return r, nil
+	})
+	switch {
+	case err != nil:
+		return nil, &apns.FailedMessage{ErrorString: err.Error(), APNsID: id, Message: m}
+	case e != nil:
+		return nil, &apns.FailedMessage{Detail: e, APNsID: id, Message: m}
+	}
+	r := &apns.Receipt{
+		APNsID:      resp.Header.Get("apns-id"),
+		UniqueID:    resp.Header.Get("apns-unique-id"),
+		Environment: s.env,
+		Message:     m,
+	}
+	if r.APNsID == "" {
+		r.APNsID = id
+	}
+	return r, nil
+}
+
+// doはnewRequestで作成したリクエストをAPNsへ送信して成功したレスポンスを返す。
+// APNsがエラーを返した場合はProtocolErrorを返す。
+// プロバイダトークンの期限切れで失敗した場合は、トークンを更新して一度だけ再送する。
+func (s *sender) do(ctx context.Context, newRequest func(token string) (*http.Request, error)) (*response, *apns.ProtocolError, error) {
+	var token string
+	if s.tokens != nil {
+		t, err := s.tokens.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		token = t
+	}
+	resp, e, err := s.retry(ctx, newRequest, token)
+	if e != nil && e.ReasonCode() == apns.ReasonExpiredProviderToken && s.tokens != nil && s.tokens.Expire(token) {
+		token, err = s.tokens.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, e, err = s.retry(ctx, newRequest, token)
+	}
+	return resp, e, err
+}
+
+// retryはnewRequestで作成したリクエストを送信する。
+// APNsが接続を閉じたか、GOAWAYやリセットで接続が切れたために失敗した場合は、
+// ConnectionPolicyに従って再接続して再送する。
+func (s *sender) retry(ctx context.Context, newRequest func(token string) (*http.Request, error), token string) (*response, *apns.ProtocolError, error) {
+	for n := int32(0); ; n++ {
+		c := s.conn()
+		resp, e, err := s.roundTrip(c, newRequest, token)
+		if !needsReconnect(e) && !isConnError(err) || n >= s.policy.MaxReconnects {
+			return resp, e, err
+		}
+		c.reconnect()
+		if err := throttle.Sleep(ctx, s.policy.ReconnectDelay); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// responseはAPNsからの成功レスポンスをあらわす。
+type response struct {
+	Header http.Header
+	Body   []byte
+}
+
+func (s *sender) roundTrip(c *conn, newRequest func(token string) (*http.Request, error), token string) (*response, *apns.ProtocolError, error) {
+	r, err := newRequest(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.do(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, parseError(resp.StatusCode, resp.Header, body, r.Header.Get("apns-id")), nil
+	}
+	return &response{Header: resp.Header, Body: body}, nil, nil
+}
+
+func (s *sender) newRequest(ctx context.Context, m *apns.Message, token string) (*http.Request, error) {
+	u := s.baseURL + "/3/device/" + hex.EncodeToString(m.Token)
+	if m.ChannelID != "" {
+		u = s.baseURL + "/4/broadcasts/apps/" + apns.BundleID(m.Topic)
+	}
+	r, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(m.Payload))
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("apns-expiration", strconv.FormatUint(uint64(m.Expir), 10))
+	if m.Priority != 0 {
+		r.Header.Set("apns-priority", strconv.Itoa(int(m.Priority)))
+	}
+	if m.Topic != "" {
+		r.Header.Set("apns-topic", m.Topic)
+	}
+	if m.CollapseID != "" {
+		r.Header.Set("apns-collapse-id", m.CollapseID)
+	}
+	if m.PushType != "" {
+		r.Header.Set("apns-push-type", m.PushType)
+	}
+	if m.ChannelID != "" {
+		r.Header.Set("apns-channel-id", m.ChannelID)
+	}
+	setToken(r, token)
+	return r, nil
+}
+
+func setToken(r *http.Request, token string) {
+	if token != "" {
+		r.Header.Set("Authorization", "bearer "+token)
+	}
+}
+
+// parseErrorはAPNsのエラーレスポンスからProtocolErrorを作る。
+// レスポンスにapns-idが無ければ、リクエストに付けたidをセットする。
+func parseError(statusCode int, header http.Header, body []byte, id string) *apns.ProtocolError {
+	e := &apns.ProtocolError{
+		StatusCode: statusCode,
+		APNsID:     header.Get("apns-id"),
+		UniqueID:   header.Get("apns-unique-id"),
+		RequestID:  header.Get("apns-request-id"),
+	}
+	if e.APNsID == "" {
+		e.APNsID = id
+	}
+	var v errorBody
+	if err := json.Unmarshal(body, &v); err != nil {
+		e.Reason = http.StatusText(statusCode)
+		return e
+	}
+	e.Reason = string(v.Reason)
+	if v.Timestamp > 0 {
+		e.Time = time.Unix(0, v.Timestamp*int64(time.Millisecond))
+	}
+	return e
+}
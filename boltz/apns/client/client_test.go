@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/apns/apnstest"
+)
+
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+}
+
+func newTestServer(t *testing.T, h http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	s := httptest.NewUnstartedServer(h)
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestDo(t *testing.T) {
+	gone := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("Proto = %s; want HTTP/2", r.Proto)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "bearer ") {
+			t.Errorf("Authorization = %q; want bearer token", r.Header.Get("Authorization"))
+		}
+		if v := r.Header.Get("apns-topic"); v != "com.example.app" {
+			t.Errorf("apns-topic = %q; want %q", v, "com.example.app")
+		}
+		switch r.URL.Path {
+		case "/3/device/01":
+			w.WriteHeader(http.StatusOK)
+		case "/3/device/02":
+			w.WriteHeader(http.StatusGone)
+			fmt.Fprintf(w, `{"reason":"Unregistered","timestamp":%d}`, gone.UnixNano()/int64(time.Millisecond))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"reason":"BadDeviceToken"}`)
+		}
+	})
+	req := &apns.Request{
+		Addr: s.URL + "/",
+		Credential: &apns.Credential{
+			Issuer:     "TEAMID",
+			KeyID:      "KEYID",
+			PrivateKey: newTestKey(t),
+		},
+		Messages: []*apns.Message{
+			{Token: []byte{0x01}, Payload: []byte(`{}`), Topic: "com.example.app"},
+			{Token: []byte{0x02}, Payload: []byte(`{}`), Topic: "com.example.app"},
+			{Token: []byte{0x03}, Payload: []byte(`{}`), Topic: "com.example.app", APNsID: "123e4567-e89b-12d3-a456-426614174000"},
+		},
+	}
+	c := &Client{Transport: s.Client().Transport}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if n := len(resp.FailedMessages); n != 2 {
+		t.Fatalf("len(FailedMessages) = %d; want 2", n)
+	}
+	tab := []struct {
+		Message    *apns.Message
+		StatusCode int
+		Reason     apns.Reason
+		Time       time.Time
+	}{
+		{Message: req.Messages[1], StatusCode: http.StatusGone, Reason: apns.ReasonUnregistered, Time: gone},
+		{Message: req.Messages[2], StatusCode: http.StatusBadRequest, Reason: apns.ReasonBadDeviceToken},
+	}
+	for i, v := range tab {
+		f := resp.FailedMessages[i]
+		if f.Message != v.Message {
+			t.Errorf("FailedMessages[%d].Message = %v; want %v", i, f.Message, v.Message)
+		}
+		if f.Detail == nil {
+			t.Errorf("FailedMessages[%d].Detail = nil; ErrorString = %q", i, f.ErrorString)
+			continue
+		}
+		if f.Detail.StatusCode != v.StatusCode || f.Detail.ReasonCode() != v.Reason || !f.Detail.Time.Equal(v.Time) {
+			t.Errorf("FailedMessages[%d].Detail = %+v; want %d %s %v", i, f.Detail, v.StatusCode, v.Reason, v.Time)
+		}
+		if !f.Detail.InvalidToken() {
+			t.Errorf("FailedMessages[%d].Detail.InvalidToken() = false; want true", i)
+		}
+		// サーバがapns-idを返さなくても、送信に使ったidが分かる
+		if f.Detail.APNsID == "" || f.APNsID != f.Detail.APNsID {
+			t.Errorf("FailedMessages[%d] APNsID = %q, Detail.APNsID = %q; want the sent apns-id", i, f.APNsID, f.Detail.APNsID)
+		}
+	}
+	if id := resp.FailedMessages[1].APNsID; id != req.Messages[2].APNsID {
+		t.Errorf("FailedMessages[1].APNsID = %q; want %q", id, req.Messages[2].APNsID)
+	}
+
+	// 接続できない場合もapns-idを返す
+	s.Close()
+	resp, err = c.Do(context.Background(), &apns.Request{Addr: s.URL, Credential: req.Credential, Messages: req.Messages[:1]})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 1 || resp.FailedMessages[0].ErrorString == "" || resp.FailedMessages[0].APNsID == "" {
+		t.Errorf("FailedMessages = %+v; want ErrorString with APNsID", resp.FailedMessages)
+	}
+}
+
+func TestDoLegacyAddr(t *testing.T) {
+	req := &apns.Request{
+		Addr:       "gateway.push.apple.com:2195",
+		Credential: &apns.Credential{},
+	}
+	var c Client
+	if _, err := c.Do(context.Background(), req); err == nil {
+		t.Errorf("Do(%q) = nil; want an error", req.Addr)
+	}
+}
+
+func TestDoInferPushType(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	cred := &apns.Credential{
+		Issuer:             "TEAMID",
+		KeyID:              "KEYID",
+		PrivateKey:         newTestKey(t),
+		InsecureSkipVerify: true,
+	}
+	if err := s.AddCredential(cred); err != nil {
+		t.Fatal(err)
+	}
+	background := []byte(`{"aps":{"content-available":1}}`)
+	req := &apns.Request{
+		Addr:       s.URL,
+		Credential: cred,
+		Messages: []*apns.Message{
+			{Token: []byte{1}, Topic: "com.example.app", Payload: background},
+			{Token: []byte{2}, Topic: "com.example.app", Payload: background, PushType: apns.PushTypeAlert},
+		},
+		InferPushType: true,
+	}
+	var c Client
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Message != req.Messages[1] {
+		t.Errorf("Warnings = %+v; want a warning for the second message", resp.Warnings)
+	}
+	a := make(map[string]*apnstest.Notification)
+	for _, n := range s.Notifications() {
+		a[n.Token] = n
+	}
+	if n := a["01"]; n == nil || n.PushType != apns.PushTypeBackground || n.Priority != 5 {
+		t.Errorf("Notification(01) = %+v; want background with priority 5", n)
+	}
+	if n := a["02"]; n == nil || n.PushType != apns.PushTypeAlert {
+		t.Errorf("Notification(02) = %+v; want explicit alert", n)
+	}
+	if m := req.Messages[0]; m.PushType != "" || m.Priority != 0 {
+		t.Errorf("Messages[0] = %+v; want unchanged", m)
+	}
+	if len(resp.Receipts) != 2 || resp.Receipts[0].Message != req.Messages[0] {
+		t.Errorf("Receipts = %+v; want original messages", resp.Receipts)
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/net/http2"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+// 1接続あたりの最大同時ストリーム数のデフォルト値
+const defaultMaxConcurrentStreams = 100
+
+// connPolicyはpにデフォルト値を適用したポリシーを返す。
+func connPolicy(p *apns.ConnectionPolicy) apns.ConnectionPolicy {
+	var v apns.ConnectionPolicy
+	if p != nil {
+		v = *p
+	}
+	if v.Connections <= 0 {
+		v.Connections = 1
+	}
+	if v.MaxConcurrentStreams <= 0 {
+		v.MaxConcurrentStreams = defaultMaxConcurrentStreams
+	}
+	if v.MaxReconnects < 0 {
+		v.MaxReconnects = 0
+	}
+	return v
+}
+
+// connはAPNsへのひとつのHTTP/2接続をあらわす。
+type conn struct {
+	client *http.Client
+	sem    chan struct{} // 同時ストリーム数を制限する
+}
+
+func newConn(t http.RoundTripper, policy apns.ConnectionPolicy) *conn {
+	return &conn{
+		client: &http.Client{Transport: t},
+		sem:    make(chan struct{}, policy.MaxConcurrentStreams),
+	}
+}
+
+// newTransportはpolicyに従ってひとつのHTTP/2接続を保持するTransportを返す。
+func newTransport(cred *apns.Credential, policy apns.ConnectionPolicy) (*http.Transport, error) {
+	config, err := tlsConfig(cred)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		TLSClientConfig:   config,
+		ForceAttemptHTTP2: true,
+		// 最初のリクエストが並行しても接続をひとつだけ開く
+		MaxConnsPerHost: 1,
+		HTTP2: &http.HTTP2Config{
+			// 上限に達しても新しい接続を開かず、既存のストリームが終わるのを待つ
+			StrictMaxConcurrentRequests: true,
+			SendPingTimeout:             policy.PingInterval,
+			PingTimeout:                 policy.PingTimeout,
+		},
+	}, nil
+}
+
+func (c *conn) do(r *http.Request) (*http.Response, error) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+	defer func() { <-c.sem }()
+	return c.client.Do(r)
+}
+
+// reconnectは現在の接続を閉じて、次のリクエストで新しい接続を開かせる。
+func (c *conn) reconnect() {
+	if t, ok := c.client.Transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+// connはリクエストに使う接続をラウンドロビンで選ぶ。
+func (s *sender) conn() *conn {
+	i := atomic.AddUint32(&s.next, 1)
+	return s.conns[int(i%uint32(len(s.conns)))]
+}
+
+// closeIdleConnectionsはsのすべての接続のうち、使用中でないものを閉じる。
+func (s *sender) closeIdleConnections() {
+	for _, c := range s.conns {
+		c.reconnect()
+	}
+}
+
+// needsReconnectはeがAPNsが接続を閉じたことによる失敗ならtrueを返す。
+func needsReconnect(e *apns.ProtocolError) bool {
+	return e != nil && (e.ReasonCode() == apns.ReasonIdleTimeout || e.ReasonCode() == apns.ReasonShutdown)
+}
+
+// isConnErrorはerrがGOAWAYや接続のリセットなど、HTTP/2接続が切れたことによる失敗ならtrueを返す。
+// net/httpに組み込まれたHTTP/2実装はGoAwayErrorを公開していないので、メッセージでも判定する。
+func isConnError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var goAway http2.GoAwayError
+	if errors.As(err, &goAway) {
+		return true
+	}
+	var stream http2.StreamError
+	if errors.As(err, &stream) {
+		// サーバが処理せずに拒否したストリーム
+		return stream.Code == http2.ErrCodeRefusedStream
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	s := err.Error()
+	return strings.Contains(s, "http2: server sent GOAWAY") || strings.Contains(s, "http2: client connection lost")
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/apns/apnstest"
+)
+
+func TestConnPolicy(t *testing.T) {
+	p := connPolicy(nil)
+	if p.Connections != 1 || p.MaxConcurrentStreams != defaultMaxConcurrentStreams || p.MaxReconnects != 0 {
+		t.Errorf("connPolicy(nil) = %+v", p)
+	}
+	p = connPolicy(&apns.ConnectionPolicy{Connections: 4, MaxConcurrentStreams: 10, MaxReconnects: -1})
+	if p.Connections != 4 || p.MaxConcurrentStreams != 10 || p.MaxReconnects != 0 {
+		t.Errorf("connPolicy = %+v", p)
+	}
+}
+
+func TestDoConnections(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		conns   = make(map[net.Conn]bool)
+		streams int32
+		peak    int32
+	)
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&streams, 1)
+		defer atomic.AddInt32(&streams, -1)
+		mu.Lock()
+		if n > peak {
+			peak = n
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	s.EnableHTTP2 = true
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns[c] = true
+			mu.Unlock()
+		}
+	}
+	s.StartTLS()
+	defer s.Close()
+
+	var messages []*apns.Message
+	for i := 0; i < 50; i++ {
+		messages = append(messages, &apns.Message{Token: []byte{byte(i)}, Topic: "com.example.app", Payload: []byte(`{}`)})
+	}
+	var c Client
+	req := &apns.Request{
+		Addr: s.URL,
+		Credential: &apns.Credential{
+			Issuer:             "TEAMID",
+			KeyID:              "KEYID",
+			PrivateKey:         newTestKey(t),
+			InsecureSkipVerify: true,
+		},
+		Messages:   messages,
+		Connection: &apns.ConnectionPolicy{Connections: 3, MaxConcurrentStreams: 2},
+	}
+	// 同じ接続先へのリクエストは接続を使い回す
+	for i := 0; i < 5; i++ {
+		resp, err := c.Do(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if len(resp.Receipts) != len(messages) {
+			t.Errorf("len(Receipts) = %d; want %d", len(resp.Receipts), len(messages))
+		}
+	}
+	c.CloseIdleConnections()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 3 {
+		t.Errorf("connections = %d; want 3", len(conns))
+	}
+	if peak > 6 {
+		t.Errorf("peak streams = %d; want <= 6", peak)
+	}
+}
+
+func TestDoReconnect(t *testing.T) {
+	var n int32
+	s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1) <= 2 {
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"reason":"Shutdown"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	req := &apns.Request{
+		Addr:       s.URL,
+		Credential: &apns.Credential{InsecureSkipVerify: true},
+		Messages:   []*apns.Message{{Token: []byte{1}, Payload: []byte(`{}`)}},
+		Connection: &apns.ConnectionPolicy{MaxReconnects: 1},
+	}
+	var c Client
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 1 || resp.FailedMessages[0].Detail == nil || resp.FailedMessages[0].Detail.ReasonCode() != apns.ReasonShutdown {
+		t.Errorf("FailedMessages = %+v; want %s", resp.FailedMessages, apns.ReasonShutdown)
+	}
+	if v := atomic.LoadInt32(&n); v != 2 {
+		t.Errorf("requests = %d; want 2", v)
+	}
+
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.Receipts) != 1 {
+		t.Errorf("Receipts = %+v; want success after reconnect", resp.FailedMessages)
+	}
+}
+
+func TestDoReconnectGoAway(t *testing.T) {
+	s := apnstest.NewServer()
+	defer s.Close()
+	cred := &apns.Credential{
+		Issuer:             "TEAMID",
+		KeyID:              "KEYID",
+		PrivateKey:         newTestKey(t),
+		InsecureSkipVerify: true,
+	}
+	if err := s.AddCredential(cred); err != nil {
+		t.Fatal(err)
+	}
+	// 送信の途中でGOAWAYを受け取っても、再接続して残りを送る
+	s.SetResponse("02", apnstest.GoAway())
+	req := &apns.Request{
+		Addr:       s.URL,
+		Credential: cred,
+		Messages: []*apns.Message{
+			{Token: []byte{1}, Topic: "com.example.app", Payload: []byte(`{}`)},
+			{Token: []byte{2}, Topic: "com.example.app", Payload: []byte(`{}`)},
+			{Token: []byte{3}, Topic: "com.example.app", Payload: []byte(`{}`)},
+		},
+		Connection: &apns.ConnectionPolicy{MaxConcurrentStreams: 1, MaxReconnects: 1},
+	}
+	var c Client
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.Receipts) != 3 || len(resp.FailedMessages) != 0 {
+		t.Errorf("Receipts = %+v, FailedMessages = %+v; want all messages sent", resp.Receipts, resp.FailedMessages)
+	}
+	tokens := make(map[string]int)
+	for _, n := range s.Notifications() {
+		tokens[n.Token]++
+	}
+	if tokens["01"] != 1 || tokens["02"] != 2 || tokens["03"] != 1 {
+		t.Errorf("notifications = %v; want 02 resent once", tokens)
+	}
+
+	// 再接続しないポリシーでは失敗として返す
+	s.SetResponse("02", apnstest.GoAway())
+	req.Connection = &apns.ConnectionPolicy{MaxConcurrentStreams: 1}
+	req.Messages = req.Messages[1:2]
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 1 || resp.FailedMessages[0].ErrorString == "" {
+		t.Errorf("FailedMessages = %+v; want a connection error", resp.FailedMessages)
+	}
+}
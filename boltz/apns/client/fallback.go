@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+)
+
+// sendWithFallbackはmをprimaryへ送信する。
+// sandboxがnilでなく、開発環境のトークンと思われるエラーで失敗した場合はsandboxが返すsenderへ再送して、
+// その結果を返す。両方の環境で拒否された場合に限りトークン無効のエラーを返すことになる。
+func sendWithFallback(ctx context.Context, primary *sender, sandbox func() (*sender, error), m *apns.Message) (*apns.Receipt, *apns.FailedMessage) {
+	r, f := primary.send(ctx, m)
+	if sandbox == nil || f == nil || !needsFallback(f.Detail) {
+		return r, f
+	}
+	s, err := sandbox()
+	if err != nil {
+		return nil, &apns.FailedMessage{ErrorString: err.Error(), Message: m}
+	}
+	return s.send(ctx, m)
+}
+
+// needsFallbackはeが開発環境へ再送すべきエラーならtrueを返す。
+func needsFallback(e *apns.ProtocolError) bool {
+	if e == nil {
+		return false
+	}
+	r := e.ReasonCode()
+	return r == apns.ReasonBadDeviceToken || r == apns.ReasonBadCertificateEnvironment
+}
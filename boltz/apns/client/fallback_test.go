@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/BoltzEngine/apis/boltz/apns"
+	"github.com/BoltzEngine/apis/boltz/apns/apnstest"
+)
+
+func TestDoSandboxFallback(t *testing.T) {
+	production := apnstest.NewServer()
+	defer production.Close()
+	sandbox := apnstest.NewServer()
+	defer sandbox.Close()
+
+	// 01: 開発環境のトークン
+	// 02: 両方の環境で無効なトークン
+	// 03: 開発環境が一時的に利用できない
+	production.SetResponse("01", apnstest.BadDeviceToken())
+	production.SetResponse("02", apnstest.BadDeviceToken())
+	production.SetResponse("03", apnstest.BadDeviceToken())
+	sandbox.SetResponse("02", apnstest.BadDeviceToken())
+	sandbox.SetResponse("03", apnstest.Shutdown())
+
+	cred := &apns.Credential{
+		Issuer:             "TEAMID",
+		KeyID:              "KEYID",
+		PrivateKey:         newTestKey(t),
+		InsecureSkipVerify: true,
+	}
+	for _, s := range []*apnstest.Server{production, sandbox} {
+		if err := s.AddCredential(cred); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newMessage := func(token byte) *apns.Message {
+		return &apns.Message{Token: []byte{token}, Topic: "com.example.app", Payload: []byte(`{}`)}
+	}
+	req := &apns.Request{
+		Addr:            production.URL,
+		Credential:      cred,
+		Messages:        []*apns.Message{newMessage(1), newMessage(2), newMessage(3), newMessage(4)},
+		SandboxFallback: true,
+		SandboxAddr:     sandbox.URL,
+	}
+	var c Client
+	// 開発環境へ再送するメッセージが無ければ、開発環境へは接続しない
+	resp, err := c.Do(context.Background(), &apns.Request{
+		Addr:            production.URL,
+		Credential:      cred,
+		Messages:        []*apns.Message{newMessage(4)},
+		SandboxFallback: true,
+		SandboxAddr:     sandbox.URL,
+	})
+	if err != nil || len(resp.Receipts) != 1 {
+		t.Fatalf("Do = %+v, %v; want 1 receipt", resp, err)
+	}
+	if n := len(c.senders); n != 1 {
+		t.Errorf("senders = %d; want 1", n)
+	}
+
+	resp, err = c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	envs := make(map[*apns.Message]apns.Environment)
+	for _, r := range resp.Receipts {
+		envs[r.Message] = r.Environment
+	}
+	if env := envs[req.Messages[0]]; env != apns.EnvironmentDevelopment {
+		t.Errorf("Environment(01) = %q; want %q", env, apns.EnvironmentDevelopment)
+	}
+	if env := envs[req.Messages[3]]; env != apns.EnvironmentProduction {
+		t.Errorf("Environment(04) = %q; want %q", env, apns.EnvironmentProduction)
+	}
+
+	if len(resp.FailedMessages) != 2 {
+		t.Fatalf("len(FailedMessages) = %d; want 2", len(resp.FailedMessages))
+	}
+	for _, f := range resp.FailedMessages {
+		switch f.Message {
+		case req.Messages[1]:
+			if f.Detail == nil || !f.Detail.InvalidToken() {
+				t.Errorf("02: Detail = %+v; want invalid token", f.Detail)
+			}
+		case req.Messages[2]:
+			if f.Detail == nil || f.Detail.InvalidToken() || f.Detail.ReasonCode() != apns.ReasonShutdown {
+				t.Errorf("03: Detail = %+v; want %s", f.Detail, apns.ReasonShutdown)
+			}
+		default:
+			t.Errorf("unexpected failure: %+v", f)
+		}
+	}
+}
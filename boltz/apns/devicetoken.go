@@ -0,0 +1,92 @@
+package apns
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// デバイストークンのバイト数。
+// 現在のトークンは32バイトだが、Appleは長さが変わり得るとしているので上限を設けて許容する。
+const (
+	MinDeviceTokenSize = 32
+	MaxDeviceTokenSize = 100
+)
+
+// OddLengthTokenErrorはhexエンコードされたトークンの桁数が奇数であることをあらわす。
+type OddLengthTokenError struct {
+	Length int
+}
+
+func (e *OddLengthTokenError) Error() string {
+	return fmt.Sprintf("apns: device token has odd length %d", e.Length)
+}
+
+// InvalidTokenCharErrorはトークンに16進数でない文字が含まれることをあらわす。
+type InvalidTokenCharError struct {
+	Char   rune
+	Offset int // 空白などを取り除いた後の位置
+}
+
+func (e *InvalidTokenCharError) Error() string {
+	return fmt.Sprintf("apns: device token has invalid character %q at offset %d", e.Char, e.Offset)
+}
+
+// TokenSizeErrorはデコードしたトークンのバイト数が範囲外であることをあらわす。
+type TokenSizeError struct {
+	Size int
+}
+
+func (e *TokenSizeError) Error() string {
+	return fmt.Sprintf("apns: device token size %d; want %d to %d bytes", e.Size, MinDeviceTokenSize, MaxDeviceTokenSize)
+}
+
+// ParseDeviceTokenはhexエンコードされたデバイストークンをデコードする。
+// 大文字と小文字のどちらも受け付け、NSDataのdescription形式("<740f4707 bebcf74f ...>")の
+// 空白と前後の山括弧は取り除く。
+// 問題があれば*OddLengthTokenError、*InvalidTokenCharError、*TokenSizeErrorのいずれかを返す。
+func ParseDeviceToken(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "<")
+	s = strings.TrimSuffix(s, ">")
+	s = strings.Map(func(c rune) rune {
+		if c == ' ' {
+			return -1
+		}
+		return c
+	}, s)
+	for i, c := range s {
+		if !isHexDigit(c) {
+			return nil, &InvalidTokenCharError{Char: c, Offset: i}
+		}
+	}
+	if len(s)%2 != 0 {
+		return nil, &OddLengthTokenError{Length: len(s)}
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < MinDeviceTokenSize || len(b) > MaxDeviceTokenSize {
+		return nil, &TokenSizeError{Size: len(b)}
+	}
+	return b, nil
+}
+
+// NormalizeDeviceTokenはsを検査して、小文字のhexエンコードに正規化したトークンを返す。
+func NormalizeDeviceToken(s string) (string, error) {
+	b, err := ParseDeviceToken(s)
+	if err != nil {
+		return "", err
+	}
+	return FormatDeviceToken(b), nil
+}
+
+// FormatDeviceTokenはbを小文字のhexエンコードで返す。
+func FormatDeviceToken(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func isHexDigit(c rune) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,38 @@
+package apns
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDeviceToken(t *testing.T) {
+	const token = "740f4707bebcf74f9b7c25d48e3358945f6aa01da5ddb387462c7eaf61bb78ad"
+	tab := []struct {
+		S   string
+		Err error
+	}{
+		{S: token},
+		{S: strings.ToUpper(token)},
+		{S: "<740f4707 bebcf74f 9b7c25d4 8e335894 5f6aa01d a5ddb387 462c7eaf 61bb78ad>"},
+		{S: " " + token + "\n"},
+		{S: token[1:], Err: &OddLengthTokenError{Length: 63}},
+		{S: token[:62] + "zz", Err: &InvalidTokenCharError{Char: 'z', Offset: 62}},
+		{S: "<740f-4707>", Err: &InvalidTokenCharError{Char: '-', Offset: 4}},
+		{S: token[:32], Err: &TokenSizeError{Size: 16}},
+		{S: "", Err: &TokenSizeError{Size: 0}},
+		{S: strings.Repeat("ab", MaxDeviceTokenSize+1), Err: &TokenSizeError{Size: MaxDeviceTokenSize + 1}},
+	}
+	for _, v := range tab {
+		s, err := NormalizeDeviceToken(v.S)
+		if v.Err == nil {
+			if err != nil || s != token {
+				t.Errorf("NormalizeDeviceToken(%q) = %q, %v; want %q", v.S, s, err, token)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, v.Err) {
+			t.Errorf("NormalizeDeviceToken(%q) = %v; want %v", v.S, err, v.Err)
+		}
+	}
+}
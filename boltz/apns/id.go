@@ -0,0 +1,37 @@
+package apns
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewAPNsIDはapns-idに使うランダムなUUID(version 4)を返す。
+func NewAPNsID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IsValidAPNsIDはsがapns-idとして使える正規形のUUID(8-4-4-4-12)ならtrueを返す。
+func IsValidAPNsID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
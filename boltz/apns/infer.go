@@ -0,0 +1,94 @@
+package apns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PushTypeWarningは明示されたapns-push-typeまたは優先度がペイロードと矛盾していることをあらわす。
+// 明示された値で送信するが、通知が破棄される可能性がある。
+type PushTypeWarning struct {
+	// 明示されたapns-push-typeと優先度
+	PushType string
+	Priority uint8
+	// TopicとPayloadから推測したapns-push-typeと優先度(優先度は0ならAPNsのデフォルト)
+	InferredPushType string
+	InferredPriority uint8
+	// 対象のメッセージ
+	Message *Message
+}
+
+func (w *PushTypeWarning) String() string {
+	if w.PushType != "" && w.PushType != w.InferredPushType {
+		return fmt.Sprintf("apns: push type %q contradicts payload (inferred %q)", w.PushType, w.InferredPushType)
+	}
+	return fmt.Sprintf("apns: priority %d contradicts %s payload (inferred %d)", w.Priority, w.InferredPushType, w.InferredPriority)
+}
+
+// InferPushTypeはtopicとpayloadから送るべきapns-push-typeと優先度を推測する。
+// トピックの接尾辞がプッシュタイプを決めている場合(.voipなど)はそれに従う。
+// それ以外では、apsにalert、badge、soundのいずれかを含めばalert、
+// content-availableだけを含めば優先度5のbackgroundとする。
+// 優先度が0の場合はAPNsのデフォルトで構わないことをあらわす。
+func InferPushType(topic string, payload []byte) (pushType string, priority uint8) {
+	if strings.HasPrefix(topic, MDMTopicPrefix) {
+		return PushTypeMdm, PrioritySentImmediately
+	}
+	if t := pushTypeOfTopic(topic); t != "" {
+		return t, 0
+	}
+	var v struct {
+		Aps map[string]json.RawMessage `json:"aps"`
+	}
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return PushTypeAlert, 0
+	}
+	for _, key := range []string{"alert", "badge", "sound"} {
+		if _, ok := v.Aps[key]; ok {
+			return PushTypeAlert, 0
+		}
+	}
+	if isTrue(v.Aps["content-available"]) {
+		return PushTypeBackground, PrioritySentAtPowerSaving
+	}
+	return PushTypeAlert, 0
+}
+
+// pushTypeOfTopicはtopicの接尾辞が決めるプッシュタイプを返す。
+// 複数の接尾辞に一致する場合は長い方を選ぶ(.voip-pttは.voipより優先)。
+func pushTypeOfTopic(topic string) string {
+	var pushType, suffix string
+	for t, s := range topicSuffixes {
+		if len(s) > len(suffix) && strings.HasSuffix(topic, s) {
+			pushType, suffix = t, s
+		}
+	}
+	return pushType
+}
+
+// InferPushTypeはmのPushTypeとPriorityが空であれば、TopicとPayloadから推測した値をセットする。
+// 明示された値は変更しない。明示された値が推測と矛盾する場合は*PushTypeWarningを返す。
+func (m *Message) InferPushType() *PushTypeWarning {
+	pushType, priority := InferPushType(m.Topic, m.Payload)
+	w := &PushTypeWarning{
+		PushType:         m.PushType,
+		Priority:         m.Priority,
+		InferredPushType: pushType,
+		InferredPriority: priority,
+		Message:          m,
+	}
+	if m.PushType == "" {
+		m.PushType = pushType
+	}
+	if m.Priority == 0 && m.PushType == pushType {
+		m.Priority = priority
+	}
+	switch {
+	case w.PushType != "" && w.PushType != pushType:
+		return w
+	case w.Priority != 0 && priority != 0 && w.Priority != priority:
+		return w
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package apns
+
+import "testing"
+
+func TestInferPushType(t *testing.T) {
+	tab := []struct {
+		Topic    string
+		Payload  string
+		PushType string
+		Priority uint8
+	}{
+		{Topic: "com.example.app", Payload: `{"aps":{"alert":"hi"}}`, PushType: PushTypeAlert},
+		{Topic: "com.example.app", Payload: `{"aps":{"badge":1}}`, PushType: PushTypeAlert},
+		{Topic: "com.example.app", Payload: `{"aps":{"content-available":1}}`, PushType: PushTypeBackground, Priority: 5},
+		{Topic: "com.example.app", Payload: `{"aps":{"content-available":1,"sound":"default"}}`, PushType: PushTypeAlert},
+		{Topic: "com.example.app", Payload: `{}`, PushType: PushTypeAlert},
+		{Topic: "com.example.app", Payload: `not json`, PushType: PushTypeAlert},
+		{Topic: "com.example.app.voip", Payload: `{"aps":{"content-available":1}}`, PushType: PushTypeVoIP},
+		{Topic: "com.example.app.voip-ptt", Payload: `{}`, PushType: PushTypePushToTalk},
+		{Topic: "com.example.app.complication", Payload: `{}`, PushType: PushTypeComplication},
+		{Topic: "com.apple.mgmt.External.x", Payload: `{"mdm":"magic"}`, PushType: PushTypeMdm, Priority: 10},
+	}
+	for _, v := range tab {
+		pushType, priority := InferPushType(v.Topic, []byte(v.Payload))
+		if pushType != v.PushType || priority != v.Priority {
+			t.Errorf("InferPushType(%q, %s) = %q, %d; want %q, %d", v.Topic, v.Payload, pushType, priority, v.PushType, v.Priority)
+		}
+	}
+}
+
+func TestMessageInferPushType(t *testing.T) {
+	background := []byte(`{"aps":{"content-available":1}}`)
+	tab := []struct {
+		Message  Message
+		PushType string
+		Priority uint8
+		Warning  bool
+	}{
+		{Message: Message{Payload: background}, PushType: PushTypeBackground, Priority: 5},
+		{Message: Message{Payload: background, PushType: PushTypeAlert}, PushType: PushTypeAlert, Warning: true},
+		{Message: Message{Payload: background, Priority: 10}, PushType: PushTypeBackground, Priority: 10, Warning: true},
+		{Message: Message{Payload: []byte(`{"aps":{"alert":"hi"}}`), PushType: PushTypeBackground}, PushType: PushTypeBackground, Warning: true},
+		{Message: Message{Payload: []byte(`{"aps":{"alert":"hi"}}`), Priority: 5}, PushType: PushTypeAlert, Priority: 5},
+		{Message: Message{Topic: "com.example.app.voip", Payload: []byte(`{}`)}, PushType: PushTypeVoIP},
+	}
+	for i, v := range tab {
+		m := v.Message
+		w := m.InferPushType()
+		if m.PushType != v.PushType || m.Priority != v.Priority {
+			t.Errorf("%d: InferPushType = %q, %d; want %q, %d", i, m.PushType, m.Priority, v.PushType, v.Priority)
+		}
+		if (w != nil) != v.Warning {
+			t.Errorf("%d: warning = %v; want %v", i, w, v.Warning)
+		}
+		if w != nil && w.Message != &m {
+			t.Errorf("%d: warning.Message = %p; want %p", i, w.Message, &m)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package apns
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Live Activityのevent
+const (
+	LiveActivityEventStart  = "start"
+	LiveActivityEventUpdate = "update"
+	LiveActivityEventEnd    = "end"
+)
+
+var (
+	errMissingTimestamp      = errors.New("apns: live activity requires timestamp")
+	errMissingContentState   = errors.New("apns: live activity requires content-state")
+	errMissingAttributes     = errors.New("apns: live activity start event requires attributes-type and attributes")
+	errUnexpectedAttributes  = errors.New("apns: attributes are allowed only for start event")
+	errUnexpectedDismissal   = errors.New("apns: dismissal-date is allowed only for end event")
+	errMissingContentChanged = errors.New("apns: widgets push requires content-changed")
+)
+
+// Validateはpが、pushTypeの通知として必要なキーを含んでいるかを検査する。
+func (p *Payload) Validate(pushType string) error {
+	switch pushType {
+	case PushTypeLiveActivity:
+		return p.Aps.validateLiveActivity()
+	case PushTypeWidgets:
+		if !p.Aps.ContentChanged {
+			return errMissingContentChanged
+		}
+	}
+	return nil
+}
+
+func (aps *Aps) validateLiveActivity() error {
+	switch aps.Event {
+	case LiveActivityEventStart:
+		if aps.AttributesType == "" || aps.Attributes == nil {
+			return errMissingAttributes
+		}
+	case LiveActivityEventUpdate, LiveActivityEventEnd:
+		if aps.AttributesType != "" || aps.Attributes != nil {
+			return errUnexpectedAttributes
+		}
+	default:
+		return fmt.Errorf("apns: unknown live activity event %q", aps.Event)
+	}
+	if aps.Timestamp == 0 {
+		return errMissingTimestamp
+	}
+	// endでもcontent-stateはロック画面に残る最終状態になるので必須
+	if aps.ContentState == nil {
+		return errMissingContentState
+	}
+	if aps.Event != LiveActivityEventEnd && aps.DismissalDate != 0 {
+		return errUnexpectedDismissal
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package apns
+
+import (
+	"testing"
+)
+
+func TestValidateLiveActivity(t *testing.T) {
+	state := map[string]int{"score": 1}
+	tab := []struct {
+		Aps Aps
+		OK  bool
+	}{
+		{Aps: Aps{Event: LiveActivityEventStart, Timestamp: 1, ContentState: state, AttributesType: "Game", Attributes: state}, OK: true},
+		{Aps: Aps{Event: LiveActivityEventStart, Timestamp: 1, ContentState: state}, OK: false},
+		{Aps: Aps{Event: LiveActivityEventUpdate, Timestamp: 1, ContentState: state, StaleDate: 2}, OK: true},
+		{Aps: Aps{Event: LiveActivityEventUpdate, Timestamp: 1}, OK: false},
+		{Aps: Aps{Event: LiveActivityEventUpdate, ContentState: state}, OK: false},
+		{Aps: Aps{Event: LiveActivityEventUpdate, Timestamp: 1, ContentState: state, DismissalDate: 2}, OK: false},
+		{Aps: Aps{Event: LiveActivityEventUpdate, Timestamp: 1, ContentState: state, AttributesType: "Game", Attributes: state}, OK: false},
+		{Aps: Aps{Event: LiveActivityEventEnd, Timestamp: 1, ContentState: state, DismissalDate: 2}, OK: true},
+		{Aps: Aps{Event: LiveActivityEventEnd, Timestamp: 1, DismissalDate: 2}, OK: false},
+		{Aps: Aps{Event: "pause", Timestamp: 1, ContentState: state}, OK: false},
+		{Aps: Aps{}, OK: false},
+	}
+	for _, v := range tab {
+		p := Payload{Aps: v.Aps}
+		err := p.Validate(PushTypeLiveActivity)
+		if (err == nil) != v.OK {
+			t.Errorf("Validate(%+v) = %v; want ok=%v", v.Aps, err, v.OK)
+		}
+	}
+}
+
+func TestEncodeLiveActivity(t *testing.T) {
+	p := Payload{
+		Aps: Aps{
+			Event:         LiveActivityEventEnd,
+			Timestamp:     1700000000,
+			ContentState:  map[string]int{"score": 3},
+			DismissalDate: 1700003600,
+		},
+	}
+	b, err := p.Encode(PushTypeLiveActivity)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	const want = `{"aps":{"event":"end","content-state":{"score":3},"timestamp":1700000000,"dismissal-date":1700003600}}`
+	if s := string(b); s != want {
+		t.Errorf("Encode = %s; want %s", s, want)
+	}
+}
+
+func TestValidateWidgets(t *testing.T) {
+	p := Payload{}
+	if err := p.Validate(PushTypeWidgets); err == nil {
+		t.Errorf("Validate(widgets) without content-changed = nil; want an error")
+	}
+	p.Aps.ContentChanged = true
+	if err := p.Validate(PushTypeWidgets); err != nil {
+		t.Errorf("Validate(widgets) = %v", err)
+	}
+}
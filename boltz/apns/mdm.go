@@ -0,0 +1,82 @@
+package apns
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MDMプッシュ証明書のUIDに入っているトピックの接頭辞
+const MDMTopicPrefix = "com.apple.mgmt."
+
+// DefaultMDMLifetimeはMDMの通知をAPNsに保存させる期間のデフォルト値。
+// 端末はチェックインした時点でキューにあるコマンドをすべて取得するので、
+// オフラインの端末にも届くよう長めに保存させる。
+const DefaultMDMLifetime = 24 * time.Hour
+
+var (
+	errMissingPushMagic      = errors.New("apns: missing PushMagic")
+	errNotMDMTopic           = errors.New("apns: mdm push requires topic beginning with " + MDMTopicPrefix)
+	errNotMDMCertificate     = errors.New("apns: certificate is not an MDM push certificate")
+	errMissingMDMDeviceToken = errors.New("apns: missing device token")
+)
+
+// MDMMessageはMDMサーバから端末へのウェイクアップ通知をあらわす。
+type MDMMessage struct {
+	// 端末がTokenUpdateで送ってきたデバイストークン
+	Token []byte
+	// 端末がTokenUpdateで送ってきたPushMagic
+	PushMagic string
+	// MDMプッシュ証明書のUID(MDMTopicで取得できる)
+	Topic string
+	// APNsが通知を破棄する時刻(ゼロ値なら現在時刻からDefaultMDMLifetime後)
+	Expiration time.Time
+}
+
+type mdmPayload struct {
+	MDM string `json:"mdm"`
+}
+
+// Messageはmを送信するMessageを返す。nowは有効期限の基準に使う。
+// MDMの通知はapsを含まないペイロード、apns-push-type mdm、優先度10で送る。
+func (m *MDMMessage) Message(now time.Time) (*Message, error) {
+	if len(m.Token) == 0 {
+		return nil, errMissingMDMDeviceToken
+	}
+	if m.PushMagic == "" {
+		return nil, errMissingPushMagic
+	}
+	if !strings.HasPrefix(m.Topic, MDMTopicPrefix) {
+		return nil, errNotMDMTopic
+	}
+	payload, err := json.Marshal(&mdmPayload{MDM: m.PushMagic})
+	if err != nil {
+		return nil, err
+	}
+	expir := m.Expiration
+	if expir.IsZero() {
+		expir = now.Add(DefaultMDMLifetime)
+	}
+	return &Message{
+		Expir:    uint32(expir.Unix()),
+		Token:    m.Token,
+		Payload:  payload,
+		Priority: PrioritySentImmediately,
+		Topic:    m.Topic,
+		PushType: PushTypeMdm,
+	}, nil
+}
+
+// MDMTopicはcredのMDMプッシュ証明書からトピック(SubjectのUID)を取り出す。
+// MDMプッシュ証明書でなければエラーを返す。
+func MDMTopic(cred *Credential) (string, error) {
+	info, err := InspectCertificate(cred)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(info.BundleID, MDMTopicPrefix) {
+		return "", errNotMDMCertificate
+	}
+	return info.BundleID, nil
+}
@@ -0,0 +1,58 @@
+package apns
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMDMMessage(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	mdm := &MDMMessage{
+		Token:     []byte{1, 2, 3},
+		PushMagic: "5B0A9E4F-3C5A-4C1B-8F2B-0D5D0A6B7C8E",
+		Topic:     "com.apple.mgmt.External.1b2c3d4e-aaaa-bbbb-cccc-1234567890ab",
+	}
+	m, err := mdm.Message(now)
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if want := `{"mdm":"5B0A9E4F-3C5A-4C1B-8F2B-0D5D0A6B7C8E"}`; string(m.Payload) != want {
+		t.Errorf("Payload = %s; want %s", m.Payload, want)
+	}
+	if m.PushType != PushTypeMdm || m.Priority != PrioritySentImmediately || m.Topic != mdm.Topic {
+		t.Errorf("Message = %+v", m)
+	}
+	if want := uint32(now.Add(DefaultMDMLifetime).Unix()); m.Expir != want {
+		t.Errorf("Expir = %d; want %d", m.Expir, want)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+
+	for _, v := range []MDMMessage{
+		{PushMagic: "magic", Topic: mdm.Topic},
+		{Token: []byte{1}, Topic: mdm.Topic},
+		{Token: []byte{1}, PushMagic: "magic", Topic: "com.example.app"},
+	} {
+		if _, err := v.Message(now); err == nil {
+			t.Errorf("Message(%+v) = nil; want an error", v)
+		}
+	}
+
+	m.Priority = PrioritySentAtPowerSaving
+	m.Topic = "com.example.app"
+	e, ok := m.Validate().(*ValidationError)
+	if !ok || len(e.Violations) != 2 || e.Violations[0].Reason != ReasonBadTopic || e.Violations[1].Reason != ReasonBadPriority {
+		t.Errorf("Validate = %v; want BadTopic and BadPriority", m.Validate())
+	}
+}
+
+func TestMDMTopic(t *testing.T) {
+	const topic = "com.apple.mgmt.External.1b2c3d4e-aaaa-bbbb-cccc-1234567890ab"
+	if s, err := MDMTopic(newCertCredential(t, "APSP:"+topic, topic)); err != nil || s != topic {
+		t.Errorf("MDMTopic = %q, %v; want %q", s, err, topic)
+	}
+	if _, err := MDMTopic(newCertCredential(t, "APSP:com.example.app", "com.example.app")); err == nil {
+		t.Errorf("MDMTopic(app certificate) = nil; want an error")
+	}
+}
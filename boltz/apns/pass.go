@@ -0,0 +1,59 @@
+package apns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Walletのパスタイプ識別子の接頭辞
+const PassTopicPrefix = "pass."
+
+var (
+	errNotPassTopic       = errors.New("apns: pass update requires topic beginning with " + PassTopicPrefix)
+	errNotPassCertificate = errors.New("apns: certificate is not a pass type certificate")
+)
+
+// NewPassUpdateMessageはWalletのパスに更新を知らせるMessageを返す。
+// tokenはパスを登録した端末が送ってきたプッシュトークン、
+// passTypeIDはパスタイプ識別子(pass.で始まる)をあらわす。
+// 通知を受けた端末はWebサービスから更新されたパスを取得する。
+func NewPassUpdateMessage(token []byte, passTypeID string) (*Message, error) {
+	if !strings.HasPrefix(passTypeID, PassTopicPrefix) {
+		return nil, errNotPassTopic
+	}
+	return &Message{
+		Token:   token,
+		Payload: []byte(`{}`),
+		Topic:   passTypeID,
+	}, nil
+}
+
+// PassTopicはcredのパスタイプ証明書からパスタイプ識別子(SubjectのUID)を取り出す。
+// パスタイプ証明書でなければエラーを返す。
+func PassTopic(cred *Credential) (string, error) {
+	info, err := InspectCertificate(cred)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(info.BundleID, PassTopicPrefix) {
+		return "", errNotPassCertificate
+	}
+	return info.BundleID, nil
+}
+
+// ValidatePassTopicはtopicへのパスの更新通知をcredの証明書で送信できるかを検査する。
+// パスの更新通知は、そのパスタイプ識別子の証明書でしか送信できない。
+func ValidatePassTopic(cred *Credential, topic string) error {
+	if !strings.HasPrefix(topic, PassTopicPrefix) {
+		return errNotPassTopic
+	}
+	passTypeID, err := PassTopic(cred)
+	if err != nil {
+		return err
+	}
+	if passTypeID != topic {
+		return fmt.Errorf("apns: certificate is for %s, not %s", passTypeID, topic)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package apns
+
+import "testing"
+
+func TestPassUpdateMessage(t *testing.T) {
+	const topic = "pass.com.example.loyalty"
+	m, err := NewPassUpdateMessage([]byte{1}, topic)
+	if err != nil {
+		t.Fatalf("NewPassUpdateMessage: %v", err)
+	}
+	if string(m.Payload) != `{}` || m.Topic != topic {
+		t.Errorf("NewPassUpdateMessage = %+v", m)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	if _, err := NewPassUpdateMessage([]byte{1}, "com.example.app"); err == nil {
+		t.Errorf("NewPassUpdateMessage(app topic) = nil; want an error")
+	}
+
+	cred := newCertCredential(t, "Pass Type ID: "+topic, topic)
+	if s, err := PassTopic(cred); err != nil || s != topic {
+		t.Errorf("PassTopic = %q, %v; want %q", s, err, topic)
+	}
+	tab := []struct {
+		Cred  *Credential
+		Topic string
+		OK    bool
+	}{
+		{Cred: cred, Topic: topic, OK: true},
+		{Cred: cred, Topic: "pass.com.example.coupon"},
+		{Cred: cred, Topic: "com.example.app"},
+		{Cred: newCertCredential(t, "Pass Type ID: com.example.app", "com.example.app"), Topic: "com.example.app"},
+	}
+	for _, v := range tab {
+		err := ValidatePassTopic(v.Cred, v.Topic)
+		if (err == nil) != v.OK {
+			t.Errorf("ValidatePassTopic(%q) = %v; want ok=%v", v.Topic, err, v.OK)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package apns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MaxPayloadSizeは通常の通知で送信できるペイロードの最大バイト数。
+	MaxPayloadSize = 4096
+	// MaxVoIPPayloadSizeはVoIP通知で送信できるペイロードの最大バイト数。
+	MaxVoIPPayloadSize = 5120
+)
+
+const (
+	InterruptionLevelPassive       = "passive"
+	InterruptionLevelActive        = "active"
+	InterruptionLevelTimeSensitive = "time-sensitive"
+	InterruptionLevelCritical      = "critical"
+)
+
+var (
+	errReservedKey = errors.New("apns: custom key \"aps\" is reserved")
+)
+
+// PayloadSizeErrorはペイロードがAPNsの上限を超えた場合のエラーをあらわす。
+type PayloadSizeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *PayloadSizeError) Error() string {
+	return fmt.Sprintf("apns: payload size %d exceeds the limit %d bytes", e.Size, e.Limit)
+}
+
+// MaxPayloadSizeForはpushTypeで送信できるペイロードの最大バイト数を返す。
+func MaxPayloadSizeFor(pushType string) int {
+	if pushType == PushTypeVoIP {
+		return MaxVoIPPayloadSize
+	}
+	return MaxPayloadSize
+}
+
+// Alertはapsディクショナリのalertをあらわす。
+type Alert struct {
+	Title           string   `json:"title,omitempty"`
+	Subtitle        string   `json:"subtitle,omitempty"`
+	Body            string   `json:"body,omitempty"`
+	LaunchImage     string   `json:"launch-image,omitempty"`
+	TitleLocKey     string   `json:"title-loc-key,omitempty"`
+	TitleLocArgs    []string `json:"title-loc-args,omitempty"`
+	SubtitleLocKey  string   `json:"subtitle-loc-key,omitempty"`
+	SubtitleLocArgs []string `json:"subtitle-loc-args,omitempty"`
+	LocKey          string   `json:"loc-key,omitempty"`
+	LocArgs         []string `json:"loc-args,omitempty"`
+}
+
+// Soundはapsディクショナリのsoundをあらわす。
+// CriticalとVolumeがゼロ値の場合はNameだけの文字列としてエンコードする。
+type Sound struct {
+	Name     string
+	Critical bool
+	Volume   float64 // 0.0〜1.0 (Criticalの場合のみ有効)
+}
+
+func (s Sound) MarshalJSON() ([]byte, error) {
+	if !s.Critical && s.Volume == 0 {
+		return json.Marshal(s.Name)
+	}
+	v := struct {
+		Critical int     `json:"critical,omitempty"`
+		Name     string  `json:"name"`
+		Volume   float64 `json:"volume,omitempty"`
+	}{
+		Name:   s.Name,
+		Volume: s.Volume,
+	}
+	if s.Critical {
+		v.Critical = 1
+	}
+	return json.Marshal(&v)
+}
+
+// Apsはペイロードのapsディクショナリをあらわす。
+type Aps struct {
+	Alert *Alert
+	// バッジの数値。nilならバッジを変更しない。0ならバッジを消す。
+	Badge            *int
+	Sound            *Sound
+	ThreadID         string
+	Category         string
+	ContentAvailable bool
+	MutableContent   bool
+	// InterruptionLevel* のいずれか
+	InterruptionLevel string
+	// 0.0〜1.0。nilなら指定しない。
+	RelevanceScore  *float64
+	TargetContentID string
+
+	// Live Activity only
+	Event          string      // LiveActivityEvent* のいずれか
+	ContentState   interface{} // ActivityKitのContentState
+	Timestamp      int64       // Unix time
+	StaleDate      int64       // Unix time
+	DismissalDate  int64       // Unix time (endの場合のみ)
+	AttributesType string      // startの場合のみ
+	Attributes     interface{} // startの場合のみ
+
+	// Widgets only
+	ContentChanged bool
+}
+
+func (aps Aps) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Alert             *Alert   `json:"alert,omitempty"`
+		Badge             *int     `json:"badge,omitempty"`
+		Sound             *Sound   `json:"sound,omitempty"`
+		ThreadID          string   `json:"thread-id,omitempty"`
+		Category          string   `json:"category,omitempty"`
+		ContentAvailable  int      `json:"content-available,omitempty"`
+		MutableContent    int      `json:"mutable-content,omitempty"`
+		InterruptionLevel string   `json:"interruption-level,omitempty"`
+		RelevanceScore    *float64 `json:"relevance-score,omitempty"`
+		TargetContentID   string   `json:"target-content-id,omitempty"`
+
+		Event          string      `json:"event,omitempty"`
+		ContentState   interface{} `json:"content-state,omitempty"`
+		Timestamp      int64       `json:"timestamp,omitempty"`
+		StaleDate      int64       `json:"stale-date,omitempty"`
+		DismissalDate  int64       `json:"dismissal-date,omitempty"`
+		AttributesType string      `json:"attributes-type,omitempty"`
+		Attributes     interface{} `json:"attributes,omitempty"`
+
+		ContentChanged bool `json:"content-changed,omitempty"`
+	}{
+		Alert:             aps.Alert,
+		Badge:             aps.Badge,
+		Sound:             aps.Sound,
+		ThreadID:          aps.ThreadID,
+		Category:          aps.Category,
+		InterruptionLevel: aps.InterruptionLevel,
+		RelevanceScore:    aps.RelevanceScore,
+		TargetContentID:   aps.TargetContentID,
+
+		Event:          aps.Event,
+		ContentState:   aps.ContentState,
+		Timestamp:      aps.Timestamp,
+		StaleDate:      aps.StaleDate,
+		DismissalDate:  aps.DismissalDate,
+		AttributesType: aps.AttributesType,
+		Attributes:     aps.Attributes,
+
+		ContentChanged: aps.ContentChanged,
+	}
+	if aps.ContentAvailable {
+		v.ContentAvailable = 1
+	}
+	if aps.MutableContent {
+		v.MutableContent = 1
+	}
+	return json.Marshal(&v)
+}
+
+// PayloadはAPNsへ送信するJSONペイロードをあらわす。
+type Payload struct {
+	Aps Aps
+	// apsと同じ階層に置くアプリ固有のキー
+	Custom map[string]interface{}
+}
+
+// Setはアプリ固有のキーを追加する。
+func (p *Payload) Set(key string, value interface{}) {
+	if p.Custom == nil {
+		p.Custom = make(map[string]interface{})
+	}
+	p.Custom[key] = value
+}
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Custom)+1)
+	for k, v := range p.Custom {
+		if k == "aps" {
+			return nil, errReservedKey
+		}
+		m[k] = v
+	}
+	m["aps"] = &p.Aps
+	return json.Marshal(m)
+}
+
+// Encodeはpをエンコードしたバイト列を返す。
+// pushTypeの上限サイズを超える場合は*PayloadSizeErrorを返す。
+func (p *Payload) Encode(pushType string) ([]byte, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	if limit := MaxPayloadSizeFor(pushType); len(b) > limit {
+		return nil, &PayloadSizeError{Size: len(b), Limit: limit}
+	}
+	return b, nil
+}
@@ -0,0 +1,101 @@
+package apns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPayloadEncode(t *testing.T) {
+	zero := 0
+	score := 0.5
+	tab := []struct {
+		Payload Payload
+		JSON    string
+	}{
+		{
+			Payload: Payload{},
+			JSON:    `{"aps":{}}`,
+		},
+		{
+			Payload: Payload{
+				Aps: Aps{
+					Alert: &Alert{Title: "t", Body: "b"},
+					Badge: &zero,
+					Sound: &Sound{Name: "default"},
+				},
+			},
+			JSON: `{"aps":{"alert":{"title":"t","body":"b"},"badge":0,"sound":"default"}}`,
+		},
+		{
+			Payload: Payload{
+				Aps: Aps{
+					Alert:             &Alert{LocKey: "K", LocArgs: []string{"a"}},
+					Sound:             &Sound{Name: "alarm.caf", Critical: true, Volume: 0.8},
+					InterruptionLevel: InterruptionLevelCritical,
+					RelevanceScore:    &score,
+				},
+			},
+			JSON: `{"aps":{"alert":{"loc-key":"K","loc-args":["a"]},"sound":{"critical":1,"name":"alarm.caf","volume":0.8},"interruption-level":"critical","relevance-score":0.5}}`,
+		},
+		{
+			Payload: Payload{
+				Aps: Aps{
+					ContentAvailable: true,
+					MutableContent:   true,
+					ThreadID:         "th",
+					Category:         "c",
+					TargetContentID:  "id",
+				},
+				Custom: map[string]interface{}{"k": "v"},
+			},
+			JSON: `{"aps":{"thread-id":"th","category":"c","content-available":1,"mutable-content":1,"target-content-id":"id"},"k":"v"}`,
+		},
+	}
+	for _, v := range tab {
+		b, err := v.Payload.Encode(PushTypeAlert)
+		if err != nil {
+			t.Errorf("Encode(%+v): %v", v.Payload, err)
+			continue
+		}
+		if s := string(b); s != v.JSON {
+			t.Errorf("Encode(%+v) = %s; want %s", v.Payload, s, v.JSON)
+		}
+	}
+}
+
+func TestPayloadEncodeReservedKey(t *testing.T) {
+	var p Payload
+	p.Set("aps", 1)
+	if _, err := p.Encode(PushTypeAlert); err == nil {
+		t.Errorf("Encode with custom key \"aps\" = nil; want an error")
+	}
+}
+
+func TestPayloadEncodeSize(t *testing.T) {
+	tab := []struct {
+		PushType string
+		Size     int
+		OK       bool
+	}{
+		{PushType: PushTypeAlert, Size: MaxPayloadSize, OK: true},
+		{PushType: PushTypeAlert, Size: MaxPayloadSize + 1, OK: false},
+		{PushType: PushTypeVoIP, Size: MaxPayloadSize + 1, OK: true},
+		{PushType: PushTypeVoIP, Size: MaxVoIPPayloadSize, OK: true},
+		{PushType: PushTypeVoIP, Size: MaxVoIPPayloadSize + 1, OK: false},
+	}
+	const empty = `{"aps":{},"x":""}`
+	for _, v := range tab {
+		var p Payload
+		p.Set("x", strings.Repeat("a", v.Size-len(empty)))
+		b, err := p.Encode(v.PushType)
+		if v.OK {
+			if err != nil || len(b) != v.Size {
+				t.Errorf("Encode(%s, %d bytes) = %d, %v; want no error", v.PushType, v.Size, len(b), err)
+			}
+			continue
+		}
+		if _, ok := err.(*PayloadSizeError); !ok {
+			t.Errorf("Encode(%s, %d bytes) = %v; want *PayloadSizeError", v.PushType, v.Size, err)
+		}
+	}
+}
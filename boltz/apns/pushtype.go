@@ -0,0 +1,60 @@
+package apns
+
+import (
+	"strings"
+)
+
+// topicSuffixesはプッシュタイプごとにapns-topicの末尾に付けるべき文字列をあらわす。
+// ここに含まれないプッシュタイプはBundle IDをそのままトピックにする。
+var topicSuffixes = map[string]string{
+	PushTypeVoIP:         ".voip",
+	PushTypeComplication: ".complication",
+	PushTypeFileProvider: ".pushkit.fileprovider",
+	PushTypeLiveActivity: ".push-type.liveactivity",
+	PushTypeLocation:     ".location-query",
+	PushTypePushToTalk:   ".voip-ptt",
+	PushTypeWidgets:      ".push-type.widgets",
+}
+
+var knownPushTypes = map[string]bool{
+	PushTypeAlert:        true,
+	PushTypeBackground:   true,
+	PushTypeVoIP:         true,
+	PushTypeComplication: true,
+	PushTypeFileProvider: true,
+	PushTypeMdm:          true,
+	PushTypeLiveActivity: true,
+	PushTypeLocation:     true,
+	PushTypePushToTalk:   true,
+	PushTypeWidgets:      true,
+}
+
+// IsKnownPushTypeはpushTypeがAPNsの定義するプッシュタイプならtrueを返す。
+func IsKnownPushType(pushType string) bool {
+	return knownPushTypes[pushType]
+}
+
+// TopicSuffixはpushTypeの通知でapns-topicの末尾に必要な文字列を返す。
+// 必要なければ空文字列を返す。
+func TopicSuffix(pushType string) string {
+	return topicSuffixes[pushType]
+}
+
+// TopicはbundleIDからpushTypeの通知に使うapns-topicを返す。
+func Topic(bundleID, pushType string) string {
+	suffix := TopicSuffix(pushType)
+	if strings.HasSuffix(bundleID, suffix) {
+		return bundleID
+	}
+	return bundleID + suffix
+}
+
+// BundleIDはtopicからプッシュタイプ固有の末尾を取り除いたBundle IDを返す。
+func BundleID(topic string) string {
+	for _, suffix := range topicSuffixes {
+		if strings.HasSuffix(topic, suffix) {
+			return strings.TrimSuffix(topic, suffix)
+		}
+	}
+	return topic
+}
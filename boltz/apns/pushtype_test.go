@@ -0,0 +1,25 @@
+package apns
+
+import "testing"
+
+func TestTopic(t *testing.T) {
+	tab := []struct {
+		BundleID string
+		PushType string
+		Topic    string
+	}{
+		{BundleID: "com.example.app", PushType: PushTypeAlert, Topic: "com.example.app"},
+		{BundleID: "com.example.app", PushType: PushTypeVoIP, Topic: "com.example.app.voip"},
+		{BundleID: "com.example.app.voip", PushType: PushTypeVoIP, Topic: "com.example.app.voip"},
+		{BundleID: "com.example.app", PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity"},
+		{BundleID: "com.example.app", PushType: PushTypeLocation, Topic: "com.example.app.location-query"},
+		{BundleID: "com.example.app", PushType: PushTypePushToTalk, Topic: "com.example.app.voip-ptt"},
+		{BundleID: "com.example.app", PushType: PushTypeWidgets, Topic: "com.example.app.push-type.widgets"},
+	}
+	for _, v := range tab {
+		s := Topic(v.BundleID, v.PushType)
+		if s != v.Topic {
+			t.Errorf("Topic(%q, %q) = %q; want %q", v.BundleID, v.PushType, s, v.Topic)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package apns
+
+// ReasonはAPNs HTTP/2 APIがエラー時に返すreasonをあらわす。
+type Reason string
+
+func (r Reason) String() string {
+	return string(r)
+}
+
+// DetailはAppleのドキュメントにあるrの説明を返す。
+// 未知のreasonの場合は空文字列を返す。
+func (r Reason) Detail() string {
+	return reasonDetails[r]
+}
+
+const (
+	ReasonBadCollapseID               = Reason("BadCollapseId")
+	ReasonBadDeviceToken              = Reason("BadDeviceToken")
+	ReasonBadExpirationDate           = Reason("BadExpirationDate")
+	ReasonBadMessageID                = Reason("BadMessageId")
+	ReasonBadPriority                 = Reason("BadPriority")
+	ReasonBadTopic                    = Reason("BadTopic")
+	ReasonDeviceTokenNotForTopic      = Reason("DeviceTokenNotForTopic")
+	ReasonDuplicateHeaders            = Reason("DuplicateHeaders")
+	ReasonIdleTimeout                 = Reason("IdleTimeout")
+	ReasonInvalidPushType             = Reason("InvalidPushType")
+	ReasonMissingDeviceToken          = Reason("MissingDeviceToken")
+	ReasonMissingTopic                = Reason("MissingTopic")
+	ReasonPayloadEmpty                = Reason("PayloadEmpty")
+	ReasonTopicDisallowed             = Reason("TopicDisallowed")
+	ReasonBadCertificate              = Reason("BadCertificate")
+	ReasonBadCertificateEnvironment   = Reason("BadCertificateEnvironment")
+	ReasonExpiredProviderToken        = Reason("ExpiredProviderToken")
+	ReasonForbidden                   = Reason("Forbidden")
+	ReasonInvalidProviderToken        = Reason("InvalidProviderToken")
+	ReasonMissingProviderToken        = Reason("MissingProviderToken")
+	ReasonBadPath                     = Reason("BadPath")
+	ReasonMethodNotAllowed            = Reason("MethodNotAllowed")
+	ReasonExpiredToken                = Reason("ExpiredToken")
+	ReasonUnregistered                = Reason("Unregistered")
+	ReasonPayloadTooLarge             = Reason("PayloadTooLarge")
+	ReasonTooManyProviderTokenUpdates = Reason("TooManyProviderTokenUpdates")
+	ReasonTooManyRequests             = Reason("TooManyRequests")
+	ReasonInternalServerError         = Reason("InternalServerError")
+	ReasonServiceUnavailable          = Reason("ServiceUnavailable")
+	ReasonShutdown                    = Reason("Shutdown")
+)
+
+var reasonDetails = map[Reason]string{
+	ReasonBadCollapseID:               "The collapse identifier exceeds the maximum allowed size",
+	ReasonBadDeviceToken:              "The specified device token was bad. Verify that the request contains a valid token and that the token matches the environment.",
+	ReasonBadExpirationDate:           "The apns-expiration value is bad.",
+	ReasonBadMessageID:                "The apns-id value is bad.",
+	ReasonBadPriority:                 "The apns-priority value is bad.",
+	ReasonBadTopic:                    "The apns-topic was invalid.",
+	ReasonDeviceTokenNotForTopic:      "The device token does not match the specified topic.",
+	ReasonDuplicateHeaders:            "One or more headers were repeated.",
+	ReasonIdleTimeout:                 "Idle time out.",
+	ReasonInvalidPushType:             "The apns-push-type value is invalid.",
+	ReasonMissingDeviceToken:          "The device token is not specified in the request :path. Verify that the :path header contains the device token.",
+	ReasonMissingTopic:                "The apns-topic header of the request was not specified and was required. The apns-topic header is mandatory when the client is connected using a certificate that supports multiple topics.",
+	ReasonPayloadEmpty:                "The message payload was empty.",
+	ReasonTopicDisallowed:             "Pushing to this topic is not allowed.",
+	ReasonBadCertificate:              "The certificate was bad.",
+	ReasonBadCertificateEnvironment:   "The client certificate was for the wrong environment.",
+	ReasonExpiredProviderToken:        "The provider token is stale and a new token should be generated.",
+	ReasonForbidden:                   "The specified action is not allowed.",
+	ReasonInvalidProviderToken:        "The provider token is not valid or the token signature could not be verified.",
+	ReasonMissingProviderToken:        "No provider certificate was used to connect to http and Authorization header was missing or no provider token was specified.",
+	ReasonBadPath:                     "The request contained a bad :path value.",
+	ReasonMethodNotAllowed:            "The specified :method was not POST.",
+	ReasonExpiredToken:                "The device token has expired.",
+	ReasonUnregistered:                "The device token is inactive for the specified topic.",
+	ReasonPayloadTooLarge:             "The message payload was too large. For regular remote notifications, the maximum size is 4KB. For VoIP notifications, the maximum size is 5KB.",
+	ReasonTooManyProviderTokenUpdates: "The provider token is being updated too often.",
+	ReasonTooManyRequests:             "Too many requests were made consecutively to the same device token.",
+	ReasonInternalServerError:         "An internal server error occurred.",
+	ReasonServiceUnavailable:          "The service is unavailable.",
+	ReasonShutdown:                    "The server is shutting down.",
+}
+
+var (
+	invalidTokenReasons = map[Reason]bool{
+		ReasonBadDeviceToken: true,
+		ReasonExpiredToken:   true,
+		ReasonUnregistered:   true,
+	}
+	temporaryReasons = map[Reason]bool{
+		ReasonIdleTimeout:                 true,
+		ReasonExpiredProviderToken:        true,
+		ReasonTooManyProviderTokenUpdates: true,
+		ReasonTooManyRequests:             true,
+		ReasonInternalServerError:         true,
+		ReasonServiceUnavailable:          true,
+		ReasonShutdown:                    true,
+	}
+	invalidPayloadReasons = map[Reason]bool{
+		ReasonBadCollapseID:          true,
+		ReasonBadExpirationDate:      true,
+		ReasonBadMessageID:           true,
+		ReasonBadPriority:            true,
+		ReasonBadTopic:               true,
+		ReasonDeviceTokenNotForTopic: true,
+		ReasonDuplicateHeaders:       true,
+		ReasonInvalidPushType:        true,
+		ReasonMissingDeviceToken:     true,
+		ReasonMissingTopic:           true,
+		ReasonPayloadEmpty:           true,
+		ReasonTopicDisallowed:        true,
+		ReasonBadPath:                true,
+		ReasonMethodNotAllowed:       true,
+		ReasonPayloadTooLarge:        true,
+	}
+	credentialReasons = map[Reason]bool{
+		ReasonBadCertificate:              true,
+		ReasonBadCertificateEnvironment:   true,
+		ReasonExpiredProviderToken:        true,
+		ReasonForbidden:                   true,
+		ReasonInvalidProviderToken:        true,
+		ReasonMissingProviderToken:        true,
+		ReasonTooManyProviderTokenUpdates: true,
+	}
+	rateLimitedReasons = map[Reason]bool{
+		ReasonTooManyProviderTokenUpdates: true,
+		ReasonTooManyRequests:             true,
+	}
+)
+
+// InvalidTokenはrがデバイストークンの無効を示す場合にtrueを返す。
+func (r Reason) InvalidToken() bool {
+	return invalidTokenReasons[r]
+}
+
+// Temporaryはrが一時的なエラーであり、再送すれば成功する可能性がある場合にtrueを返す。
+func (r Reason) Temporary() bool {
+	return temporaryReasons[r]
+}
+
+// InvalidPayloadはrがメッセージのヘッダまたはペイロードの誤りを示す場合にtrueを返す。
+func (r Reason) InvalidPayload() bool {
+	return invalidPayloadReasons[r]
+}
+
+// CredentialProblemはrが証明書またはプロバイダトークンの問題を示す場合にtrueを返す。
+func (r Reason) CredentialProblem() bool {
+	return credentialReasons[r]
+}
+
+// RateLimitedはrが送信頻度の超過を示す場合にtrueを返す。
+func (r Reason) RateLimited() bool {
+	return rateLimitedReasons[r]
+}
@@ -0,0 +1,51 @@
+package apns
+
+import (
+	"testing"
+)
+
+func TestReasonClassify(t *testing.T) {
+	tab := []struct {
+		Reason            Reason
+		InvalidToken      bool
+		Temporary         bool
+		InvalidPayload    bool
+		CredentialProblem bool
+		RateLimited       bool
+	}{
+		{Reason: ReasonBadDeviceToken, InvalidToken: true},
+		{Reason: ReasonUnregistered, InvalidToken: true},
+		{Reason: ReasonBadTopic, InvalidPayload: true},
+		{Reason: ReasonPayloadTooLarge, InvalidPayload: true},
+		{Reason: ReasonTooManyRequests, Temporary: true, RateLimited: true},
+		{Reason: ReasonTooManyProviderTokenUpdates, Temporary: true, CredentialProblem: true, RateLimited: true},
+		{Reason: ReasonExpiredProviderToken, Temporary: true, CredentialProblem: true},
+		{Reason: ReasonBadCertificate, CredentialProblem: true},
+		{Reason: ReasonShutdown, Temporary: true},
+		{Reason: Reason("Unknown")},
+	}
+	for _, v := range tab {
+		r := v.Reason
+		if r.InvalidToken() != v.InvalidToken ||
+			r.Temporary() != v.Temporary ||
+			r.InvalidPayload() != v.InvalidPayload ||
+			r.CredentialProblem() != v.CredentialProblem ||
+			r.RateLimited() != v.RateLimited {
+			t.Errorf("%s: classifiers = %v %v %v %v %v; want %+v", r,
+				r.InvalidToken(), r.Temporary(), r.InvalidPayload(), r.CredentialProblem(), r.RateLimited(), v)
+		}
+	}
+}
+
+func TestReasonDetail(t *testing.T) {
+	if s := ReasonBadTopic.Detail(); s == "" {
+		t.Errorf("%s.Detail() is empty", ReasonBadTopic)
+	}
+	if s := Reason("Unknown").Detail(); s != "" {
+		t.Errorf("Unknown.Detail() = %q; want empty", s)
+	}
+	p := &ProtocolError{StatusCode: 400, Reason: string(ReasonBadTopic)}
+	if !p.ReasonCode().InvalidPayload() {
+		t.Errorf("ProtocolError.ReasonCode().InvalidPayload() = false; want true")
+	}
+}
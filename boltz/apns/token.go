@@ -0,0 +1,161 @@
+package apns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// TokenMinLifetimeはプロバイダトークンを更新する最短間隔。
+	// これより短い間隔で更新するとAPNsはTooManyProviderTokenUpdatesを返す。
+	TokenMinLifetime = 20 * time.Minute
+	// TokenMaxLifetimeはプロバイダトークンの有効期限。
+	// これを過ぎるとAPNsはExpiredProviderTokenを返す。
+	TokenMaxLifetime = 60 * time.Minute
+	// TokenRefreshIntervalはTokenSourceがプロバイダトークンを更新する間隔。
+	TokenRefreshInterval = 40 * time.Minute
+)
+
+var (
+	ErrMissingProviderKey = errors.New("apns: missing issuer, key id or private key")
+	ErrInvalidPrivateKey  = errors.New("apns: private key must be PEM encoded EC P-256 key")
+)
+
+// HasProviderTokenはcredがJWT認証用の資格情報を含む場合にtrueを返す。
+func (cred *Credential) HasProviderToken() bool {
+	return cred.Issuer != "" || cred.KeyID != "" || len(cred.PrivateKey) > 0
+}
+
+// TokenSourceはCredentialからES256で署名したプロバイダトークンを生成する。
+// 生成したトークンはTokenRefreshIntervalの間キャッシュされる。
+// 複数のゴルーチンから同時に使っても安全。
+type TokenSource struct {
+	issuer string
+	keyID  string
+	key    *ecdsa.PrivateKey
+
+	now func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewTokenSourceはcredのIssuer、KeyID、PrivateKeyを使うTokenSourceを返す。
+func NewTokenSource(cred *Credential) (*TokenSource, error) {
+	if cred.Issuer == "" || cred.KeyID == "" || len(cred.PrivateKey) == 0 {
+		return nil, ErrMissingProviderKey
+	}
+	key, err := ParsePrivateKey(cred.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenSource{
+		issuer: cred.Issuer,
+		keyID:  cred.KeyID,
+		key:    key,
+		now:    time.Now,
+	}, nil
+}
+
+// Tokenはキャッシュしているプロバイダトークンを返す。
+// キャッシュが無いかTokenRefreshIntervalを過ぎている場合は新しく生成する。
+func (s *TokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.token != "" && now.Sub(s.issuedAt) < TokenRefreshInterval {
+		return s.token, nil
+	}
+	return s.refresh(now)
+}
+
+// ExpireはAPNsがExpiredProviderTokenを返した場合に呼ぶ。
+// tokenが現在のトークンであり、かつTokenMinLifetimeを過ぎている場合に限り
+// トークンを作り直してtrueを返す。
+// それ以外の場合は、すでに更新済みか、更新するとTooManyProviderTokenUpdatesとなるため何もしない。
+func (s *TokenSource) Expire(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token != s.token {
+		return s.token != ""
+	}
+	now := s.now()
+	if now.Sub(s.issuedAt) < TokenMinLifetime {
+		return false
+	}
+	_, err := s.refresh(now)
+	return err == nil
+}
+
+func (s *TokenSource) refresh(now time.Time) (string, error) {
+	token, err := signToken(s.key, s.issuer, s.keyID, now)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.issuedAt = now
+	return token, nil
+}
+
+func signToken(key *ecdsa.PrivateKey, issuer, keyID string, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "ES256",
+		"kid": keyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": issuer,
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	s := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(s))
+	r, t, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	t.FillBytes(sig[32:])
+	return s + "." + enc.EncodeToString(sig), nil
+}
+
+// ParsePrivateKeyはPEMエンコードされたEC P-256秘密鍵を読み込む。
+// AppleからダウンロードしたPKCS#8形式(.p8)とSEC1形式のどちらも受け付ける。
+func ParsePrivateKey(b []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		v, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k, ok := v.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidPrivateKey
+		}
+		key = k
+	}
+	if key.Curve != elliptic.P256() {
+		return nil, ErrInvalidPrivateKey
+	}
+	return key, nil
+}
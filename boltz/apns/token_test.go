@@ -0,0 +1,129 @@
+package apns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCredential(t *testing.T) *Credential {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Credential{
+		Issuer:     "TEAMID",
+		KeyID:      "KEYID",
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}),
+	}
+}
+
+func TestTokenSourceSignature(t *testing.T) {
+	cred := newTestCredential(t)
+	s, err := NewTokenSource(cred)
+	if err != nil {
+		t.Fatalf("NewTokenSource: %v", err)
+	}
+	token, err := s.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	a := strings.Split(token, ".")
+	if len(a) != 3 {
+		t.Fatalf("Token = %q; want 3 segments", token)
+	}
+	var header map[string]string
+	b, _ := base64.RawURLEncoding.DecodeString(a[0])
+	if err := json.Unmarshal(b, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header["alg"] != "ES256" || header["kid"] != cred.KeyID {
+		t.Errorf("header = %v; want alg=ES256 kid=%s", header, cred.KeyID)
+	}
+	var claims struct {
+		Issuer   string `json:"iss"`
+		IssuedAt int64  `json:"iat"`
+	}
+	b, _ = base64.RawURLEncoding.DecodeString(a[1])
+	if err := json.Unmarshal(b, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != cred.Issuer || claims.IssuedAt == 0 {
+		t.Errorf("claims = %+v; want iss=%s and iat", claims, cred.Issuer)
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(a[2])
+	if len(sig) != 64 {
+		t.Fatalf("len(signature) = %d; want 64", len(sig))
+	}
+	sum := sha256.Sum256([]byte(a[0] + "." + a[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	v := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&s.key.PublicKey, sum[:], r, v) {
+		t.Errorf("signature is not valid")
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	s, err := NewTokenSource(newTestCredential(t))
+	if err != nil {
+		t.Fatalf("NewTokenSource: %v", err)
+	}
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	t1, _ := s.Token()
+
+	now = now.Add(TokenMinLifetime - time.Second)
+	if t2, _ := s.Token(); t2 != t1 {
+		t.Errorf("Token was refreshed before TokenRefreshInterval")
+	}
+	if s.Expire(t1) {
+		t.Errorf("Expire refreshed the token before TokenMinLifetime")
+	}
+
+	now = now.Add(2 * time.Second)
+	if !s.Expire(t1) {
+		t.Errorf("Expire did not refresh the token after TokenMinLifetime")
+	}
+	t2, _ := s.Token()
+	if t2 == t1 {
+		t.Errorf("Token was not refreshed after Expire")
+	}
+	if !s.Expire(t1) {
+		t.Errorf("Expire(old token) = false; want true because of already refreshed")
+	}
+	if t3, _ := s.Token(); t3 != t2 {
+		t.Errorf("Expire(old token) refreshed the token again")
+	}
+
+	now = now.Add(TokenRefreshInterval)
+	if t3, _ := s.Token(); t3 == t2 {
+		t.Errorf("Token was not refreshed after TokenRefreshInterval")
+	}
+}
+
+func TestNewTokenSourceError(t *testing.T) {
+	tab := []*Credential{
+		{Issuer: "TEAMID", KeyID: "KEYID"},
+		{Issuer: "TEAMID", KeyID: "KEYID", PrivateKey: []byte("not a key")},
+		{KeyID: "KEYID", PrivateKey: newTestCredential(t).PrivateKey},
+	}
+	for _, cred := range tab {
+		if _, err := NewTokenSource(cred); err == nil {
+			t.Errorf("NewTokenSource(%+v) = nil; want an error", cred)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package apns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ViolationはMessageの検査で見つかった問題をあらわす。
+type Violation struct {
+	// 問題のあるMessageのフィールド名
+	Field string
+	// そのまま送信した場合にAPNsが返すと思われるreason(該当するものが無ければ空)
+	Reason Reason
+	// 問題の説明
+	Description string
+}
+
+func (v *Violation) String() string {
+	if v.Reason == "" {
+		return fmt.Sprintf("%s: %s", v.Field, v.Description)
+	}
+	return fmt.Sprintf("%s: %s (%s)", v.Field, v.Description, v.Reason)
+}
+
+// ValidationErrorはMessageの検査で見つかったすべての問題をあらわす。
+type ValidationError struct {
+	Message    *Message
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string {
+	a := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		a[i] = v.String()
+	}
+	return "apns: invalid message: " + strings.Join(a, "; ")
+}
+
+// validatorはひとつのMessageを検査する間の状態をあらわす。
+type validator struct {
+	m          *Message
+	violations []*Violation
+}
+
+func (v *validator) report(field string, reason Reason, format string, args ...interface{}) {
+	v.violations = append(v.violations, &Violation{
+		Field:       field,
+		Reason:      reason,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateはmのPushType、Topic、Priority、Payloadが
+// Appleの定めるプッシュタイプごとの規則に従っているかを検査する。
+// 問題があれば*ValidationErrorを返す。
+func (m *Message) Validate() error {
+	v := &validator{m: m}
+	v.validatePushType()
+	v.validateAPNsID()
+	v.validateTopic()
+	v.validatePriority()
+	v.validatePayload()
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Message: m, Violations: v.violations}
+}
+
+func (v *validator) validatePushType() {
+	if v.m.PushType != "" && !IsKnownPushType(v.m.PushType) {
+		v.report("PushType", ReasonInvalidPushType, "unknown push type %q", v.m.PushType)
+	}
+}
+
+func (v *validator) validateAPNsID() {
+	if v.m.APNsID != "" && !IsValidAPNsID(v.m.APNsID) {
+		v.report("APNsID", ReasonBadMessageID, "apns-id must be a canonical UUID")
+	}
+}
+
+func (v *validator) validateTopic() {
+	m := v.m
+	if m.Topic == "" {
+		if TopicSuffix(m.PushType) != "" {
+			v.report("Topic", ReasonMissingTopic, "%s push requires topic", m.PushType)
+		}
+		return
+	}
+	if m.PushType == PushTypeMdm {
+		if !strings.HasPrefix(m.Topic, MDMTopicPrefix) {
+			v.report("Topic", ReasonBadTopic, "mdm push requires topic with prefix %q", MDMTopicPrefix)
+		}
+		return
+	}
+	want := TopicSuffix(m.PushType)
+	if want != "" && !strings.HasSuffix(m.Topic, want) {
+		v.report("Topic", ReasonBadTopic, "%s push requires topic with suffix %q", m.PushType, want)
+		return
+	}
+	if m.PushType == "" {
+		return
+	}
+	for pushType, suffix := range topicSuffixes {
+		if suffix != want && len(suffix) > len(want) && strings.HasSuffix(m.Topic, suffix) {
+			v.report("Topic", ReasonTopicDisallowed, "topic %q is for %s push", m.Topic, pushType)
+			return
+		}
+	}
+}
+
+func (v *validator) validatePriority() {
+	m := v.m
+	switch m.Priority {
+	case 0, 1, PrioritySentAtPowerSaving, PrioritySentImmediately:
+	default:
+		v.report("Priority", ReasonBadPriority, "priority must be 1, 5 or 10")
+		return
+	}
+	switch m.PushType {
+	case PushTypeBackground:
+		// 優先度を省略するとAPNsは10として扱うので、明示が必要
+		if m.Priority != PrioritySentAtPowerSaving {
+			v.report("Priority", ReasonBadPriority, "background push requires priority 5")
+		}
+	case PushTypeLocation:
+		if m.Priority == 1 {
+			v.report("Priority", ReasonBadPriority, "location push requires priority 5 or 10")
+		}
+	case PushTypePushToTalk:
+		if m.Priority != 0 && m.Priority != PrioritySentImmediately {
+			v.report("Priority", ReasonBadPriority, "pushtotalk push requires priority 10")
+		}
+	case PushTypeMdm:
+		if m.Priority != 0 && m.Priority != PrioritySentImmediately {
+			v.report("Priority", ReasonBadPriority, "mdm push requires priority 10")
+		}
+	case PushTypeLiveActivity:
+		if m.Priority == 1 {
+			v.report("Priority", ReasonBadPriority, "liveactivity push requires priority 5 or 10")
+		}
+	}
+}
+
+func (v *validator) validatePayload() {
+	m := v.m
+	if len(m.Payload) == 0 {
+		v.report("Payload", ReasonPayloadEmpty, "payload is empty")
+		return
+	}
+	if limit := MaxPayloadSizeFor(m.PushType); len(m.Payload) > limit {
+		v.report("Payload", ReasonPayloadTooLarge, "payload size %d exceeds %d bytes", len(m.Payload), limit)
+	}
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(m.Payload, &payload); err != nil {
+		v.report("Payload", "", "payload must be a JSON object: %v", err)
+		return
+	}
+	var aps map[string]json.RawMessage
+	if b, ok := payload["aps"]; ok {
+		if err := json.Unmarshal(b, &aps); err != nil {
+			v.report("Payload", "", "aps must be a JSON object: %v", err)
+			return
+		}
+	}
+	switch m.PushType {
+	case PushTypeBackground:
+		if !isTrue(aps["content-available"]) {
+			v.report("Payload", "", "background push requires aps.content-available")
+		}
+		for _, key := range []string{"alert", "badge", "sound"} {
+			if _, ok := aps[key]; ok {
+				v.report("Payload", "", "background push must not contain aps.%s", key)
+			}
+		}
+	case PushTypeMdm:
+		var magic string
+		if err := json.Unmarshal(payload["mdm"], &magic); err != nil || magic == "" {
+			v.report("Payload", "", "mdm push requires PushMagic string in mdm key")
+		}
+		if aps != nil {
+			v.report("Payload", "", "mdm push must not contain aps")
+		}
+	case PushTypeLiveActivity:
+		var event string
+		json.Unmarshal(aps["event"], &event)
+		switch event {
+		case LiveActivityEventStart, LiveActivityEventUpdate, LiveActivityEventEnd:
+		default:
+			v.report("Payload", "", "liveactivity push requires aps.event start, update or end")
+		}
+		if _, ok := aps["timestamp"]; !ok {
+			v.report("Payload", "", "liveactivity push requires aps.timestamp")
+		}
+		if _, ok := aps["content-state"]; !ok {
+			v.report("Payload", "", "liveactivity push requires aps.content-state")
+		}
+		_, hasType := aps["attributes-type"]
+		_, hasAttrs := aps["attributes"]
+		switch {
+		case event == LiveActivityEventStart && (!hasType || !hasAttrs):
+			v.report("Payload", "", "liveactivity start event requires aps.attributes-type and aps.attributes")
+		case event != LiveActivityEventStart && (hasType || hasAttrs):
+			v.report("Payload", "", "aps.attributes are allowed only for liveactivity start event")
+		}
+	case PushTypeWidgets:
+		if !isTrue(aps["content-changed"]) {
+			v.report("Payload", "", "widgets push requires aps.content-changed")
+		}
+	}
+}
+
+// isTrueはbがJSONの1またはtrueである場合にtrueを返す。
+func isTrue(b json.RawMessage) bool {
+	s := string(b)
+	return s == "1" || s == "true"
+}
@@ -0,0 +1,128 @@
+package apns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessageValidate(t *testing.T) {
+	tab := []struct {
+		Message Message
+		Reasons []Reason // nilなら問題なし
+	}{
+		{
+			Message: Message{PushType: PushTypeAlert, Topic: "com.example.app", Priority: 10, Payload: []byte(`{"aps":{"alert":"hi"}}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeVoIP, Topic: "com.example.app.voip", Priority: 10, Payload: []byte(`{}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeVoIP, Topic: "com.example.app", Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadTopic},
+		},
+		{
+			Message: Message{PushType: PushTypeComplication, Topic: "com.example.app", Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadTopic},
+		},
+		{
+			Message: Message{PushType: PushTypeAlert, Topic: "com.example.app.voip", Payload: []byte(`{"aps":{"alert":"hi"}}`)},
+			Reasons: []Reason{ReasonTopicDisallowed},
+		},
+		{
+			Message: Message{PushType: PushTypeVoIP, Topic: "com.example.app.voip-ptt", Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadTopic},
+		},
+		{
+			Message: Message{Topic: "com.example.app.voip", Payload: []byte(`{}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeBackground, Topic: "com.example.app", Priority: 5, Payload: []byte(`{"aps":{"content-available":1}}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeBackground, Topic: "com.example.app", Priority: 10, Payload: []byte(`{"aps":{"content-available":1,"alert":"hi"}}`)},
+			Reasons: []Reason{ReasonBadPriority, ""},
+		},
+		{
+			Message: Message{PushType: PushTypeBackground, Topic: "com.example.app", Payload: []byte(`{"aps":{}}`)},
+			Reasons: []Reason{ReasonBadPriority, ""},
+		},
+		{
+			Message: Message{PushType: PushTypeLocation, Topic: "com.example.app.location-query", Priority: 5, Payload: []byte(`{}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeLocation, Topic: "com.example.app.location-query", Priority: 1, Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadPriority},
+		},
+		{
+			Message: Message{PushType: PushTypePushToTalk, Topic: "com.example.app.voip-ptt", Payload: []byte(`{}`)},
+		},
+		{
+			Message: Message{PushType: PushTypePushToTalk, Topic: "com.example.app.voip-ptt", Priority: 5, Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadPriority},
+		},
+		{
+			Message: Message{PushType: PushTypeAlert, Topic: "com.example.app", Priority: 3, Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonBadPriority},
+		},
+		{
+			Message: Message{PushType: PushTypeMdm, Topic: "com.apple.mgmt.External.x", Payload: []byte(`{"mdm":"magic"}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeMdm, Topic: "com.apple.mgmt.External.x", Payload: []byte(`{"aps":{}}`)},
+			Reasons: []Reason{"", ""},
+		},
+		{
+			Message: Message{PushType: "unknown", Payload: []byte(`{}`)},
+			Reasons: []Reason{ReasonInvalidPushType},
+		},
+		{
+			Message: Message{PushType: PushTypeAlert},
+			Reasons: []Reason{ReasonPayloadEmpty},
+		},
+		{
+			Message: Message{PushType: PushTypeAlert, Payload: []byte(`{"x":"` + strings.Repeat("a", MaxPayloadSize) + `"}`)},
+			Reasons: []Reason{ReasonPayloadTooLarge},
+		},
+		{
+			Message: Message{PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity", Payload: []byte(`{"aps":{"event":"update","timestamp":1,"content-state":{}}}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity", Payload: []byte(`{"aps":{"event":"update","timestamp":1}}`)},
+			Reasons: []Reason{""},
+		},
+		{
+			Message: Message{PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity", Payload: []byte(`{"aps":{"event":"start","timestamp":1,"content-state":{}}}`)},
+			Reasons: []Reason{""},
+		},
+		{
+			Message: Message{PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity", Payload: []byte(`{"aps":{"event":"start","timestamp":1,"content-state":{},"attributes-type":"A","attributes":{}}}`)},
+		},
+		{
+			Message: Message{PushType: PushTypeLiveActivity, Topic: "com.example.app.push-type.liveactivity", Payload: []byte(`{"aps":{"event":"pause"}}`)},
+			Reasons: []Reason{"", "", ""},
+		},
+	}
+	for _, v := range tab {
+		err := v.Message.Validate()
+		if v.Reasons == nil {
+			if err != nil {
+				t.Errorf("Validate(%s %q) = %v; want nil", v.Message.PushType, v.Message.Topic, err)
+			}
+			continue
+		}
+		e, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("Validate(%s %q) = %v; want *ValidationError", v.Message.PushType, v.Message.Topic, err)
+			continue
+		}
+		if len(e.Violations) != len(v.Reasons) {
+			t.Errorf("Validate(%s %q) = %v; want %d violations", v.Message.PushType, v.Message.Topic, err, len(v.Reasons))
+			continue
+		}
+		for i, r := range v.Reasons {
+			if e.Violations[i].Reason != r {
+				t.Errorf("Validate(%s %q).Violations[%d] = %v; want reason %q", v.Message.PushType, v.Message.Topic, i, e.Violations[i], r)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package gcm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// google.rpc.Status.detailsに含まれる型
+const (
+	fcmErrorType   = "type.googleapis.com/google.firebase.fcm.v1.FcmError"
+	apnsErrorType  = "type.googleapis.com/google.firebase.fcm.v1.ApnsError"
+	badRequestType = "type.googleapis.com/google.rpc.BadRequest"
+)
+
+// ApnsErrorはFCMがAPNsへ転送した際にAPNsが返したエラーをあらわす。
+type ApnsError struct {
+	StatusCode int    // APNsのHTTPステータス
+	Reason     string // APNsのreason(例: BadDeviceToken)
+}
+
+// FieldViolationはリクエストの不正なフィールドをあらわす。
+type FieldViolation struct {
+	Field       string // 例: message.android.ttl
+	Description string
+}
+
+// errorBodyはFCM HTTP v1 APIのエラーレスポンスのボディをあらわす。
+type errorBody struct {
+	Error struct {
+		Code    int               `json:"code"`
+		Message string            `json:"message"`
+		Status  string            `json:"status"`
+		Details []json.RawMessage `json:"details"`
+	} `json:"error"`
+}
+
+// errorDetailはgoogle.rpc.Status.detailsのひとつをあらわす。
+// @typeによって使うフィールドが異なる。
+type errorDetail struct {
+	Type string `json:"@type"`
+
+	// FcmError
+	ErrorCode string `json:"errorCode"`
+
+	// ApnsError
+	StatusCode int    `json:"statusCode"`
+	Reason     string `json:"reason"`
+
+	// BadRequest
+	FieldViolations []struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	} `json:"fieldViolations"`
+}
+
+// ParseAPIErrorはFCM HTTP v1 APIのエラーレスポンスをAPIErrorに変換する。
+// ボディがgoogle.rpc.Statusでなければ、HTTPステータスだけをセットしたAPIErrorを返す。
+func ParseAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	return parseAPIError(statusCode, header, body, time.Now())
+}
+
+func parseAPIError(statusCode int, header http.Header, body []byte, now time.Time) *APIError {
+	e := &APIError{
+		Code:       statusCode,
+		RetryAfter: parseRetryAfter(header.Get("Retry-After"), now),
+	}
+	var v errorBody
+	if err := json.Unmarshal(body, &v); err != nil || v.Error.Status == "" {
+		e.Status = statusOf(statusCode)
+		e.Description = http.StatusText(statusCode)
+		return e
+	}
+	if v.Error.Code != 0 {
+		e.Code = v.Error.Code
+	}
+	e.Status = v.Error.Status
+	e.Description = v.Error.Message
+	for _, b := range v.Error.Details {
+		var d errorDetail
+		if err := json.Unmarshal(b, &d); err != nil {
+			continue
+		}
+		switch d.Type {
+		case fcmErrorType:
+			e.FcmErrorCode = d.ErrorCode
+		case apnsErrorType:
+			e.ApnsError = &ApnsError{StatusCode: d.StatusCode, Reason: d.Reason}
+		case badRequestType:
+			for _, f := range d.FieldViolations {
+				e.FieldViolations = append(e.FieldViolations, &FieldViolation{
+					Field:       f.Field,
+					Description: f.Description,
+				})
+			}
+		}
+	}
+	return e
+}
+
+// statusOfはボディが無い場合にHTTPステータスから推測したgoogle.rpc.Codeを返す。
+func statusOf(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	default:
+		return "INTERNAL"
+	}
+}
+
+// parseRetryAfterはRetry-Afterヘッダの秒数またはHTTP日付をnowからの期間に変換する。
+func parseRetryAfter(s string, now time.Time) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0
+		}
+		return time.Duration(n) * time.Second
+	}
+	t, err := http.ParseTime(s)
+	if err != nil || !t.After(now) {
+		return 0
+	}
+	return t.Sub(now)
+}
+
+func (e *APIError) Error() string {
+	s := fmt.Sprintf("gcm: %d %s", e.Code, e.ErrorCode())
+	if e.Description != "" {
+		s += ": " + e.Description
+	}
+	if e.ApnsError != nil {
+		s += fmt.Sprintf(" (APNs %d %s)", e.ApnsError.StatusCode, e.ApnsError.Reason)
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package gcm
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAPIError(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	body := `{"error":{"code":401,"message":"Auth error from APNS or Web Push Service","status":"UNAUTHENTICATED","details":[
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"THIRD_PARTY_AUTH_ERROR"},
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.ApnsError","statusCode":403,"reason":"InvalidProviderToken"}]}}`
+	e := parseAPIError(http.StatusUnauthorized, http.Header{}, []byte(body), now)
+	want := &APIError{
+		Code:         401,
+		Status:       "UNAUTHENTICATED",
+		Description:  "Auth error from APNS or Web Push Service",
+		FcmErrorCode: "THIRD_PARTY_AUTH_ERROR",
+		ApnsError:    &ApnsError{StatusCode: 403, Reason: "InvalidProviderToken"},
+	}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("parseAPIError = %+v; want %+v", e, want)
+	}
+	if e.ErrorCode() != "THIRD_PARTY_AUTH_ERROR" || e.Temporary() || e.BadRegID() {
+		t.Errorf("ErrorCode = %s, Temporary = %v, BadRegID = %v", e.ErrorCode(), e.Temporary(), e.BadRegID())
+	}
+
+	body = `{"error":{"code":400,"message":"Invalid value","status":"INVALID_ARGUMENT","details":[
+		{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"message.android.ttl","description":"Invalid value"}]}]}}`
+	e = parseAPIError(http.StatusBadRequest, http.Header{}, []byte(body), now)
+	if len(e.FieldViolations) != 1 || e.FieldViolations[0].Field != "message.android.ttl" || e.ErrorCode() != "INVALID_ARGUMENT" {
+		t.Errorf("parseAPIError = %+v", e)
+	}
+
+	body = `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED","details":[
+		{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"QUOTA_EXCEEDED"}]}}`
+	e = parseAPIError(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, []byte(body), now)
+	if e.RetryAfter != 30*time.Second || e.ErrorCode() != "QUOTA_EXCEEDED" || !e.Temporary() {
+		t.Errorf("parseAPIError = %+v", e)
+	}
+
+	e = parseAPIError(http.StatusServiceUnavailable, http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, []byte("<html>"), now)
+	if e.Status != "UNAVAILABLE" || e.RetryAfter != time.Minute || !e.Temporary() {
+		t.Errorf("parseAPIError(no body) = %+v", e)
+	}
+}
+
+func TestAPIErrorCode(t *testing.T) {
+	tab := []struct {
+		Err  APIError
+		Code string
+	}{
+		{Err: APIError{Status: "NOT_FOUND", FcmErrorCode: "UNREGISTERED"}, Code: "UNREGISTERED"},
+		{Err: APIError{Status: "INVALID_ARGUMENT"}, Code: "INVALID_ARGUMENT"},
+		{Err: APIError{Status: "UNAVAILABLE", FcmErrorCode: "SOMETHING_NEW"}, Code: "UNAVAILABLE"},
+		{Err: APIError{Status: "INVALID_ARGUMENT", FcmErrorCode: "UNSPECIFIED_ERROR"}, Code: "INVALID_ARGUMENT"},
+		{Err: APIError{Status: "SOMETHING_NEW", FcmErrorCode: "SOMETHING_NEWER"}, Code: "INTERNAL"},
+	}
+	for _, v := range tab {
+		if code := v.Err.ErrorCode(); code != v.Code {
+			t.Errorf("ErrorCode(%+v) = %s; want %s", v.Err, code, v.Code)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+	"github.com/BoltzEngine/apis/boltz/internal/throttle"
+)
+
+// 接続の排出によって、ひとつのメッセージを別の接続で送り直す最大回数
+const maxDrainRetries = 5
+
+var errNoTarget = errors.New("gcm: message has no registration IDs")
+
+// doXMPPはreqに含まれるすべてのメッセージをFCM XMPP API(CCS)で送信する。
+// CCSにはマルチキャストが無いので、RegIDsの宛先ごとにToをセットしたメッセージを送る。
+// CONNECTION_DRAININGを受け取った場合は、新しい接続を開いて残りのメッセージを送る。
+// FailedMessageとSignalのMessageは、宛先ごとに分けた後のメッセージをあらわす。
+// CCSへの接続はDoのたびにTLSのハンドシェイクとSASL認証を行って開き、Doの終了時に閉じる。
+// 接続の確立には数往復かかるので、少数のメッセージを頻繁に送るよりもまとめて送ること。
+func (c *Client) doXMPP(ctx context.Context, req *gcm.Request) (*gcm.Response, error) {
+	s := &ccsSession{client: c, addr: req.URL, cred: req.Credential}
+	defer s.Close()
+	// リクエスト自体を処理できるかは、最初の接続で確かめる
+	if _, err := s.conn(ctx); err != nil {
+		return nil, err
+	}
+
+	var msgs []*gcm.Message
+	var failures []*gcm.FailedMessage
+	for _, m := range req.Messages {
+		a := splitMessage(m)
+		if len(a) == 0 {
+			failures = append(failures, &gcm.FailedMessage{ErrorString: errNoTarget.Error(), Message: m})
+			continue
+		}
+		msgs = append(msgs, a...)
+	}
+
+	results := make([]*gcm.FailedMessage, len(msgs))
+	p := throttle.NewPacer(req.BandWidth)
+	defer p.Stop()
+
+	var wg sync.WaitGroup
+	ch := make(chan int)
+	// 排出中の接続の応答を待つ間も、新しい接続の窓を埋められるようにする
+	n := 2 * maxPendingMessages
+	if n > len(msgs) {
+		n = len(msgs)
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				results[i] = s.send(ctx, msgs[i])
+			}
+		}()
+	}
+	for i, m := range msgs {
+		if err := p.Wait(ctx); err != nil {
+			results[i] = &gcm.FailedMessage{ErrorString: err.Error(), Message: m}
+			continue
+		}
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+
+	resp := &gcm.Response{FailedMessages: []*gcm.FailedMessage{}}
+	resp.FailedMessages = append(resp.FailedMessages, failures...)
+	for _, f := range results {
+		if f != nil {
+			resp.FailedMessages = append(resp.FailedMessages, f)
+		}
+	}
+	return resp, nil
+}
+
+// splitMessageはmを宛先ごとのメッセージに分ける。
+// 複数の宛先がある場合、message_idは宛先ごとに異なる値にする。
+func splitMessage(m *gcm.Message) []*gcm.Message {
+	targets := m.RegIDs
+	if m.To != "" {
+		targets = append(targets[:len(targets):len(targets)], m.To)
+	}
+	a := make([]*gcm.Message, len(targets))
+	for i, to := range targets {
+		v := *m
+		v.RegIDs = nil
+		v.To = to
+		switch {
+		case v.ID == "":
+			v.ID = newMessageID()
+		case len(targets) > 1:
+			v.ID = m.ID + "-" + strconv.Itoa(i)
+		}
+		a[i] = &v
+	}
+	return a
+}
+
+// newMessageIDはCCSへ送るメッセージのmessage_idを生成する。
+func newMessageID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// ccsSessionはひとつのgcm.Requestを処理する間のCCSへの接続をあらわす。
+// 新しいメッセージは排出中でない最新の接続で送る。
+type ccsSession struct {
+	client *Client
+	addr   string
+	cred   *gcm.Credential
+
+	mu    sync.Mutex
+	cur   *xmppConn
+	conns []*xmppConn
+}
+
+// connはメッセージを送るための接続を返す。
+// 現在の接続が排出中か切れていれば、新しい接続を開く。
+func (s *ccsSession) conn(ctx context.Context) (*xmppConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur != nil && !s.cur.isDraining() && !s.cur.isClosed() {
+		return s.cur, nil
+	}
+	x, err := s.client.dialXMPP(ctx, s.addr, s.cred, s.client.routeUpstream(s.cred.SenderID))
+	if err != nil {
+		return nil, err
+	}
+	s.cur = x
+	s.conns = append(s.conns, x)
+	return x, nil
+}
+
+// sendはmをCCSへ送信する。
+// 接続の排出や切断で応答を受け取れなかった場合は、別の接続で送り直す。
+// 一時的なnackの場合は、Client.MaxRetriesまで待って再送する。
+// 排出による送り直しはmaxDrainRetriesまでで、それを超えると通常のnackと同じく扱う。
+// 失敗または登録IDの更新があればFailedMessageを、それ以外はnilを返す。
+func (s *ccsSession) send(ctx context.Context, m *gcm.Message) *gcm.FailedMessage {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return &gcm.FailedMessage{ErrorString: err.Error(), Message: m}
+	}
+	maxRetries, minBackoff := s.client.retryPolicy()
+	for n, drains := 0, 0; ; {
+		x, err := s.conn(ctx)
+		if err != nil {
+			return &gcm.FailedMessage{ErrorString: err.Error(), Message: m}
+		}
+		v, err := x.send(ctx, m.ID, body)
+		switch {
+		case err == errConnDraining && drains < maxDrainRetries:
+			drains++
+			continue
+		case err == errStreamClosed && n < maxRetries:
+			n++
+			continue
+		case err != nil:
+			return &gcm.FailedMessage{ErrorString: err.Error(), Message: m}
+		case v.MessageType == "ack":
+			if v.RegID == "" {
+				return nil
+			}
+			return &gcm.FailedMessage{Signal: &gcm.Signal{Message: m, RegID: v.RegID}, Message: m}
+		case v.Error == gcm.ConnectionDraining && drains < maxDrainRetries:
+			drains++
+			continue
+		}
+		sig := &gcm.Signal{Message: m, Code: v.Error, Description: v.ErrorDescription}
+		if !sig.Temporary() || n >= maxRetries {
+			return &gcm.FailedMessage{Signal: sig, Message: m}
+		}
+		d := expBackoff(minBackoff, n)
+		n++
+		if err := throttle.Sleep(ctx, d); err != nil {
+			return &gcm.FailedMessage{ErrorString: err.Error(), Message: m}
+		}
+	}
+}
+
+// Closeはすべての接続を閉じる。
+// Receiverへ渡した上りメッセージがack待ちの接続は、ReceiverがAckした後で閉じる。
+func (s *ccsSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range s.conns {
+		if x.release() {
+			x.Close()
+		}
+	}
+	s.conns = nil
+	s.cur = nil
+	return nil
+}
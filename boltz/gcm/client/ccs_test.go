@@ -0,0 +1,373 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+)
+
+// ccsServerはテスト用にCCSの代わりをするXMPPサーバをあらわす。
+type ccsServer struct {
+	t         *testing.T
+	l         net.Listener
+	senderID  string
+	serverKey string
+	// 最初の接続でこの数のメッセージを受け取ったらCONNECTION_DRAININGを送る(0なら送らない)
+	drainAfter int
+	// trueなら、すべての接続で確立した直後にCONNECTION_DRAININGを送る
+	drainAll bool
+	// 排出した接続を閉じる時に、ackを返さずに捨てるメッセージ数
+	drop int
+	// 接続ごとに、確立した直後に送る上りメッセージ
+	// 最後とnilの接続以外は、送った後にCONNECTION_DRAININGを送る
+	upstream [][]*gcm.UpstreamMessage
+
+	mu         sync.Mutex
+	conns      int
+	maxPending int
+	acked      map[string]int // 宛先ごとのackの数
+	upAcked    map[string]int // 上りメッセージのIDごとにackを受け取った接続
+}
+
+func newCCSServer(t *testing.T) *ccsServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ccsServer{
+		t:         t,
+		l:         l,
+		senderID:  "123456",
+		serverKey: "server-key",
+		acked:     make(map[string]int),
+		upAcked:   make(map[string]int),
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			n := s.conns
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn, n)
+		}
+	}()
+	return s
+}
+
+func (s *ccsServer) dial(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", s.l.Addr().String())
+}
+
+func (s *ccsServer) credential() *gcm.Credential {
+	return &gcm.Credential{SenderID: s.senderID, ServerKey: s.serverKey}
+}
+
+// expectStreamはクライアントがストリームを開始するのを待つ。
+func expectStream(d *xml.Decoder) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if start, ok := t.(xml.StartElement); ok && start.Name.Local == "stream" {
+			return nil
+		}
+	}
+}
+
+// nextElementはストリームの次の子要素をvに読み込む。
+func nextElement(d *xml.Decoder, v interface{}) error {
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			return d.DecodeElement(v, &t)
+		case xml.EndElement:
+			return errStreamClosed
+		}
+	}
+}
+
+const testStreamHeader = `<?xml version="1.0"?><stream:stream from="fcm.googleapis.com" id="1" version="1.0" xmlns="jabber:client" xmlns:stream="http://etherx.jabber.org/streams">`
+
+func writeGCM(conn net.Conn, v interface{}) {
+	b, _ := json.Marshal(v)
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, b)
+	fmt.Fprintf(conn, `<message id=""><gcm xmlns="google:mobile:data">%s</gcm></message>`, buf.String())
+}
+
+func (s *ccsServer) serve(conn net.Conn, index int) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	d := xml.NewDecoder(r)
+	if err := expectStream(d); err != nil {
+		return
+	}
+	fmt.Fprint(conn, testStreamHeader+`<stream:features><mechanisms xmlns="urn:ietf:params:xml:ns:xmpp-sasl">`+
+		`<mechanism>X-OAUTH2</mechanism><mechanism>PLAIN</mechanism></mechanisms></stream:features>`)
+	var auth struct {
+		Mechanism string `xml:"mechanism,attr"`
+		Value     string `xml:",chardata"`
+	}
+	if err := nextElement(d, &auth); err != nil {
+		return
+	}
+	b, _ := base64.StdEncoding.DecodeString(auth.Value)
+	if auth.Mechanism != "PLAIN" || string(b) != "\x00"+s.senderID+"@fcm.googleapis.com\x00"+s.serverKey {
+		fmt.Fprint(conn, `<failure xmlns="urn:ietf:params:xml:ns:xmpp-sasl"><not-authorized/></failure></stream:stream>`)
+		return
+	}
+	fmt.Fprint(conn, `<success xmlns="urn:ietf:params:xml:ns:xmpp-sasl"/>`)
+
+	d = xml.NewDecoder(r)
+	if err := expectStream(d); err != nil {
+		return
+	}
+	fmt.Fprint(conn, testStreamHeader+`<stream:features><bind xmlns="urn:ietf:params:xml:ns:xmpp-bind"/>`+
+		`<session xmlns="urn:ietf:params:xml:ns:xmpp-session"/></stream:features>`)
+	var iq xmppStanza
+	if err := nextElement(d, &iq); err != nil {
+		return
+	}
+	fmt.Fprintf(conn, `<iq type="result" id="%s"><bind xmlns="urn:ietf:params:xml:ns:xmpp-bind"><jid>%s@fcm.googleapis.com/test</jid></bind></iq>`, iq.ID, s.senderID)
+
+	if s.drainAll {
+		writeGCM(conn, map[string]string{"message_type": "control", "control_type": gcm.ConnectionDraining})
+	}
+	if index < len(s.upstream) {
+		for _, m := range s.upstream[index] {
+			writeGCM(conn, m)
+		}
+		if index < len(s.upstream)-1 && s.upstream[index] != nil {
+			writeGCM(conn, map[string]string{"message_type": "control", "control_type": gcm.ConnectionDraining})
+		}
+	}
+
+	in := make(chan map[string]interface{})
+	go func() {
+		defer close(in)
+		for {
+			var v xmppStanza
+			if err := nextElement(d, &v); err != nil {
+				return
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(v.GCM), &m); err != nil {
+				s.t.Errorf("Unmarshal(%s): %v", v.GCM, err)
+				return
+			}
+			if m["message_type"] == "ack" {
+				s.mu.Lock()
+				s.upAcked[m["message_id"].(string)] = index
+				s.mu.Unlock()
+				continue
+			}
+			in <- m
+		}
+	}()
+
+	var unacked []map[string]interface{}
+	received := 0
+	draining := s.drainAll
+	for {
+		select {
+		case m, ok := <-in:
+			if !ok {
+				return
+			}
+			if draining {
+				writeGCM(conn, map[string]string{
+					"message_type": "nack",
+					"message_id":   m["message_id"].(string),
+					"from":         m["to"].(string),
+					"error":        gcm.ConnectionDraining,
+				})
+				continue
+			}
+			unacked = append(unacked, m)
+			received++
+			s.mu.Lock()
+			if len(unacked) > s.maxPending {
+				s.maxPending = len(unacked)
+			}
+			s.mu.Unlock()
+			if index == 0 && received == s.drainAfter {
+				draining = true
+				writeGCM(conn, map[string]string{"message_type": "control", "control_type": gcm.ConnectionDraining})
+			} else if len(unacked) >= maxPendingMessages {
+				s.respond(conn, unacked)
+				unacked = nil
+			}
+		case <-time.After(20 * time.Millisecond):
+			if draining {
+				// 一部のメッセージに応答しないまま接続を閉じる
+				s.respond(conn, unacked[:len(unacked)-s.drop])
+				fmt.Fprint(conn, `</stream:stream>`)
+				return
+			}
+			s.respond(conn, unacked)
+			unacked = nil
+		}
+	}
+}
+
+func (s *ccsServer) respond(conn net.Conn, msgs []map[string]interface{}) {
+	for _, m := range msgs {
+		to := m["to"].(string)
+		v := map[string]string{
+			"message_type": "ack",
+			"message_id":   m["message_id"].(string),
+			"from":         to,
+		}
+		switch to {
+		case "gone":
+			v["message_type"] = "nack"
+			v["error"] = gcm.DeviceUnregistered
+			v["error_description"] = "Device unregistered"
+		case "canon":
+			v["registration_id"] = "canon-new"
+		}
+		if v["message_type"] == "ack" {
+			s.mu.Lock()
+			s.acked[to]++
+			s.mu.Unlock()
+		}
+		writeGCM(conn, v)
+	}
+}
+
+func TestDoXMPP(t *testing.T) {
+	s := newCCSServer(t)
+	s.drainAfter = 150
+	s.drop = 10
+	regIDs := []string{"gone", "canon"}
+	for i := 0; i < 250; i++ {
+		regIDs = append(regIDs, fmt.Sprintf("tok-%d", i))
+	}
+	m := &gcm.Message{RegIDs: regIDs, Data: map[string]string{"k": "v"}}
+	req := &gcm.Request{
+		URL:        "fcm-xmpp.googleapis.com:5235",
+		Credential: s.credential(),
+		Messages:   []*gcm.Message{m},
+	}
+	c := &Client{Dial: s.dial, MinBackoff: time.Millisecond}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 2 {
+		t.Fatalf("FailedMessages = %d; want 2", len(resp.FailedMessages))
+	}
+	for _, f := range resp.FailedMessages {
+		if f.Signal == nil {
+			t.Errorf("FailedMessage = %+v; want Signal", f)
+			continue
+		}
+		switch f.Message.To {
+		case "gone":
+			if f.Signal.Code != gcm.DeviceUnregistered || !f.Signal.BadRegID() {
+				t.Errorf("Signal(gone) = %+v; want %s", f.Signal, gcm.DeviceUnregistered)
+			}
+		case "canon":
+			if f.Signal.RegID != "canon-new" || f.Signal.Code != "" {
+				t.Errorf("Signal(canon) = %+v; want canonical ID", f.Signal)
+			}
+		default:
+			t.Errorf("FailedMessage(%s) = %+v", f.Message.To, f.Signal)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range regIDs[2:] {
+		if s.acked[id] == 0 {
+			t.Errorf("%s was not delivered", id)
+		}
+	}
+	if s.conns != 2 {
+		t.Errorf("connections = %d; want 2", s.conns)
+	}
+	if s.maxPending > maxPendingMessages {
+		t.Errorf("max pending messages = %d; want <= %d", s.maxPending, maxPendingMessages)
+	}
+}
+
+func TestDoXMPPDrainRetries(t *testing.T) {
+	s := newCCSServer(t)
+	s.drainAll = true
+	req := &gcm.Request{
+		URL:        "fcm-xmpp.googleapis.com:5235",
+		Credential: s.credential(),
+		Messages:   []*gcm.Message{{To: "token"}},
+	}
+	c := &Client{Dial: s.dial, MaxRetries: 1, MinBackoff: time.Millisecond}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 1 {
+		t.Fatalf("FailedMessages = %d; want 1", len(resp.FailedMessages))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 最初の接続と、排出による送り直しの接続と、その後のnackを再送するための接続
+	if max := 1 + maxDrainRetries + 1 + c.MaxRetries; s.conns > max {
+		t.Errorf("connections = %d; want <= %d", s.conns, max)
+	}
+}
+
+func TestDoXMPPAuthFailure(t *testing.T) {
+	s := newCCSServer(t)
+	cred := s.credential()
+	cred.ServerKey = "wrong"
+	req := &gcm.Request{
+		URL:        "fcm-xmpp.googleapis.com:5235",
+		Credential: cred,
+		Messages:   []*gcm.Message{{To: "token"}},
+	}
+	c := &Client{Dial: s.dial}
+	_, err := c.Do(context.Background(), req)
+	if e, ok := err.(*AuthError); !ok || e.Condition != "not-authorized" {
+		t.Errorf("Do = %v; want AuthError not-authorized", err)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	m := &gcm.Message{ID: "m", RegIDs: []string{"a", "b"}, To: "c"}
+	a := splitMessage(m)
+	if len(a) != 3 {
+		t.Fatalf("splitMessage = %d messages; want 3", len(a))
+	}
+	for i, to := range []string{"a", "b", "c"} {
+		if a[i].To != to || a[i].RegIDs != nil || a[i].ID != fmt.Sprintf("m-%d", i) {
+			t.Errorf("splitMessage[%d] = %+v", i, a[i])
+		}
+	}
+	if a := splitMessage(&gcm.Message{To: "a"}); len(a) != 1 || a[0].ID == "" {
+		t.Errorf("splitMessage = %+v; want message ID", a)
+	}
+	if len(m.RegIDs) != 2 {
+		t.Errorf("splitMessage modified original RegIDs")
+	}
+}
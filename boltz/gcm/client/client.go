@@ -0,0 +1,362 @@
+// Package client implements FCM HTTP v1 API and XMPP API (CCS) client that executes gcm.Request.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+	"github.com/BoltzEngine/apis/boltz/internal/throttle"
+)
+
+const (
+	// レスポンスボディとして読み込む最大バイト数
+	maxResponseBody = 64 * 1024
+
+	// 同時に使うHTTP/2ストリーム数のデフォルト値
+	defaultMaxConcurrentStreams = 100
+	// 一時的なエラーを再送する最大回数のデフォルト値
+	defaultMaxRetries = 3
+	// 再送までの待ち時間の初期値と上限(Retry-Afterが無い場合)
+	defaultMinBackoff = time.Second
+	maxBackoff        = time.Minute
+)
+
+var (
+	errUnsupportedURL    = errors.New("gcm: URL is neither FCM HTTP v1 API nor XMPP API")
+	errMissingCredential = errors.New("gcm: missing credential")
+)
+
+// ClientはFCM HTTP v1 APIまたはXMPP APIへメッセージを送信するクライアントをあらわす。
+// ゼロ値のClientはRequest.Credentialから接続を作成する。
+// HTTP v1 APIの接続はリクエスト間で使い回すので、Clientは複数のリクエストで共有すること。
+type Client struct {
+	// Transportがnilでなければ、Credentialから作成する代わりに使う。(主にテスト用)
+	// トークンエンドポイントへのアクセスにも使う。
+	Transport http.RoundTripper
+	// Dialがnilでなければ、XMPP APIのTLS接続を確立する代わりに使う。(主にテスト用)
+	Dial func(ctx context.Context, addr string) (net.Conn, error)
+
+	// 同時に使うHTTP/2ストリーム数(0以下ならdefaultMaxConcurrentStreams)
+	MaxConcurrentStreams int
+	// 一時的なエラーを再送する最大回数(0ならdefaultMaxRetries、負なら再送しない)
+	// XMPP APIでは、応答を受け取る前に接続が切れた場合の再送も数える。
+	MaxRetries int
+	// Retry-Afterが無い場合の最初の再送までの待ち時間(0以下ならdefaultMinBackoff)
+	// 再送のたびに倍にする。
+	MinBackoff time.Duration
+
+	mu         sync.Mutex
+	tokens     map[string]*gcm.TokenSource
+	transports map[bool]*http.Transport // InsecureSkipVerifyごとのTransport
+	receivers  map[string]*Receiver     // SenderIDごとの上りメッセージの受け取り先
+}
+
+// jobはひとつの宛先への送信をあらわす。
+type job struct {
+	msg *gcm.Message
+	to  string
+	req *gcm.V1Request
+}
+
+// Doはreqに含まれるすべてのメッセージをreq.URLのプロトコルで送信する。
+// 個々の宛先の失敗はResponse.FailedMessagesで返し、
+// リクエスト自体を処理できない場合のみエラーを返す。
+func (c *Client) Do(ctx context.Context, req *gcm.Request) (*gcm.Response, error) {
+	v := gcm.ProtocolVersion(req.URL)
+	if v != gcm.FcmHttpV1Api && v != gcm.FcmXmppApi {
+		return nil, errUnsupportedURL
+	}
+	if req.Credential == nil {
+		return nil, errMissingCredential
+	}
+	if v == gcm.FcmXmppApi {
+		return c.doXMPP(ctx, req)
+	}
+	return c.doV1(ctx, req)
+}
+
+// doV1はreqに含まれるすべてのメッセージをFCM HTTP v1 APIで送信する。
+// HTTP v1 APIにはマルチキャストが無いので、RegIDsの宛先ごとにひとつのリクエストを送る。
+// TranslateV1が翻訳できなかった項目は無視してResponse.Warningsで返すが、宛先が無いメッセージは失敗とする。
+func (c *Client) doV1(ctx context.Context, req *gcm.Request) (*gcm.Response, error) {
+	s, err := c.newSender(req.URL, req.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*job
+	var failures []*gcm.FailedMessage
+	var warnings []*gcm.TranslationWarning
+	now := time.Now()
+	for _, m := range req.Messages {
+		reqs, report := gcm.TranslateV1(m, now)
+		if len(reqs) == 0 {
+			failures = append(failures, &gcm.FailedMessage{ErrorString: report.String(), Message: m})
+			continue
+		}
+		if !report.Empty() {
+			warnings = append(warnings, &gcm.TranslationWarning{Report: report, Message: m})
+		}
+		for _, r := range reqs {
+			jobs = append(jobs, &job{msg: m, to: targetOf(r.Message), req: r})
+		}
+	}
+
+	results := make([]*gcm.FailedMessage, len(jobs))
+	p := throttle.NewPacer(req.BandWidth)
+	defer p.Stop()
+
+	var wg sync.WaitGroup
+	ch := make(chan int)
+	n := c.MaxConcurrentStreams
+	if n <= 0 {
+		n = defaultMaxConcurrentStreams
+	}
+	if n > len(jobs) {
+		n = len(jobs)
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				results[i] = s.send(ctx, jobs[i])
+			}
+		}()
+	}
+	for i, j := range jobs {
+		if err := p.Wait(ctx); err != nil {
+			results[i] = &gcm.FailedMessage{ErrorString: err.Error(), Message: j.msg}
+			continue
+		}
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+
+	resp := &gcm.Response{FailedMessages: []*gcm.FailedMessage{}, Warnings: warnings}
+	resp.FailedMessages = append(resp.FailedMessages, failures...)
+	for _, f := range results {
+		if f != nil {
+			resp.FailedMessages = append(resp.FailedMessages, f)
+		}
+	}
+	return resp, nil
+}
+
+// targetOfはmの宛先をレガシーAPIの表記で返す。
+func targetOf(m *gcm.V1Message) string {
+	switch {
+	case m.Token != "":
+		return m.Token
+	case m.Topic != "":
+		return "/topics/" + m.Topic
+	default:
+		return m.Condition
+	}
+}
+
+// senderはひとつのgcm.Requestを処理する間の送信先と再送の設定をあらわす。
+type sender struct {
+	client     *http.Client
+	url        string
+	tokens     *gcm.TokenSource
+	maxRetries int
+	minBackoff time.Duration
+}
+
+func (c *Client) newSender(u string, cred *gcm.Credential) (*sender, error) {
+	tokens, err := c.tokenSource(cred)
+	if err != nil {
+		return nil, err
+	}
+	s := &sender{
+		client: &http.Client{Transport: c.transport(cred)},
+		url:    sendURL(u, tokens.ProjectID()),
+		tokens: tokens,
+	}
+	s.maxRetries, s.minBackoff = c.retryPolicy()
+	return s, nil
+}
+
+// retryPolicyはデフォルト値を適用した再送の最大回数と最初の待ち時間を返す。
+func (c *Client) retryPolicy() (maxRetries int, minBackoff time.Duration) {
+	maxRetries, minBackoff = c.MaxRetries, c.MinBackoff
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	return maxRetries, minBackoff
+}
+
+// expBackoffはn回目の再送までの待ち時間をminから倍々にしてmaxBackoffまでに制限して返す。
+func expBackoff(min time.Duration, n int) time.Duration {
+	d := min << uint(n)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+// tokenSourceはcredに対応するTokenSourceを返す。
+// アクセストークンは1時間有効なので、同じサービスアカウントのTokenSourceはリクエスト間で使い回す。
+func (c *Client) tokenSource(cred *gcm.Credential) (*gcm.TokenSource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.tokens[cred.ServiceAccount]; ok {
+		return s, nil
+	}
+	var hc *http.Client
+	if c.Transport != nil {
+		hc = &http.Client{Transport: c.Transport}
+	}
+	s, err := gcm.NewTokenSource(cred, hc)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokens == nil {
+		c.tokens = make(map[string]*gcm.TokenSource)
+	}
+	c.tokens[cred.ServiceAccount] = s
+	return s, nil
+}
+
+// transportはcredでFCMへ接続するTransportを返す。
+// 同じ設定のTransportはリクエスト間で使い回して、HTTP/2接続を開いたままにする。
+func (c *Client) transport(cred *gcm.Credential) http.RoundTripper {
+	if c.Transport != nil {
+		return c.Transport
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.transports[cred.InsecureSkipVerify]; ok {
+		return t
+	}
+	t := newTransport(cred)
+	if c.transports == nil {
+		c.transports = make(map[bool]*http.Transport)
+	}
+	c.transports[cred.InsecureSkipVerify] = t
+	return t
+}
+
+// CloseIdleConnectionsはリクエスト間で使い回しているHTTP/2接続のうち、使用中でないものを閉じる。
+// 閉じた接続は次のリクエストで開き直す。
+func (c *Client) CloseIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range c.transports {
+		t.CloseIdleConnections()
+	}
+}
+
+// newTransportはFCMへのひとつのHTTP/2接続でストリームを多重化するTransportを返す。
+func newTransport(cred *gcm.Credential) *http.Transport {
+	return &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: cred.InsecureSkipVerify},
+		ForceAttemptHTTP2: true,
+		MaxConnsPerHost:   1,
+		HTTP2: &http.HTTP2Config{
+			// 上限に達しても新しい接続を開かず、既存のストリームが終わるのを待つ
+			StrictMaxConcurrentRequests: true,
+		},
+	}
+}
+
+// sendURLはuがmessages:sendのURLであればそのまま、
+// そうでなければprojects/{projectID}/messages:sendを補ったURLを返す。
+func sendURL(u, projectID string) string {
+	if strings.HasSuffix(u, ":send") {
+		return u
+	}
+	return strings.TrimSuffix(u, "/") + "/projects/" + projectID + "/messages:send"
+}
+
+// sendはj.reqをFCMへ送信する。
+// 一時的なエラーの場合はRetry-Afterまたは指数バックオフで待って再送する。
+// 失敗した場合はFailedMessageを、成功した場合はnilを返す。
+func (s *sender) send(ctx context.Context, j *job) *gcm.FailedMessage {
+	body, err := json.Marshal(j.req)
+	if err != nil {
+		return &gcm.FailedMessage{ErrorString: err.Error(), Message: j.msg}
+	}
+	for n := 0; ; n++ {
+		e, err := s.do(ctx, body)
+		switch {
+		case err != nil:
+			return &gcm.FailedMessage{ErrorString: err.Error(), Message: j.msg}
+		case e == nil:
+			return nil
+		case !e.Temporary() || n >= s.maxRetries:
+			e.Message = j.msg
+			e.To = j.to
+			return &gcm.FailedMessage{Error: e, Message: j.msg}
+		}
+		if err := throttle.Sleep(ctx, s.backoff(n, e)); err != nil {
+			return &gcm.FailedMessage{ErrorString: err.Error(), Message: j.msg}
+		}
+	}
+}
+
+// backoffはn回目の再送までの待ち時間を返す。
+func (s *sender) backoff(n int, e *gcm.APIError) time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	return expBackoff(s.minBackoff, n)
+}
+
+// doはbodyをFCMへ送信する。FCMがエラーを返した場合はAPIErrorを返す。
+// アクセストークンが拒否された場合は、トークンを取得し直して一度だけ再送する。
+func (s *sender) do(ctx context.Context, body []byte) (*gcm.APIError, error) {
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.roundTrip(ctx, body, token)
+	if e != nil && e.ErrorCode() == "UNAUTHENTICATED" {
+		s.tokens.Expire(token)
+		if token, err = s.tokens.Token(ctx); err != nil {
+			return nil, err
+		}
+		e, err = s.roundTrip(ctx, body, token)
+	}
+	return e, err
+}
+
+func (s *sender) roundTrip(ctx context.Context, body []byte, token string) (*gcm.APIError, error) {
+	r, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+token)
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return gcm.ParseAPIError(resp.StatusCode, resp.Header, b), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+)
+
+// テスト用サーバへ接続させるURLのベース
+// ProtocolVersionがFcmHttpV1Apiと判定するように、ホスト名にfcmを含める。
+const testBaseURL = "https://fcm.example.com"
+
+func newTestServiceAccount(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "test-project",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})),
+		"client_email": "test@test-project.iam.gserviceaccount.com",
+		"token_uri":    testBaseURL + "/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(v)
+}
+
+// newTestServerはtokenエンドポイントとmessages:sendを持つHTTP/2サーバを起動して、
+// testBaseURLへの接続をそのサーバへ向けるTransportを返す。
+// sendには送信先トークンとAuthorizationヘッダが渡される。
+func newTestServer(t *testing.T, send func(w http.ResponseWriter, token, auth string)) http.RoundTripper {
+	t.Helper()
+	var issued int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("Proto = %s; want HTTP/2", r.Proto)
+		}
+		switch r.URL.Path {
+		case "/token":
+			n := atomic.AddInt32(&issued, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","expires_in":3600}`, n)
+		case "/v1/projects/test-project/messages:send":
+			var v gcm.V1Request
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				t.Errorf("Decode: %v", err)
+			}
+			token := v.Message.Token
+			if token == "" {
+				token = "/topics/" + v.Message.Topic
+			}
+			send(w, token, r.Header.Get("Authorization"))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	t.Cleanup(s.Close)
+
+	tr := s.Client().Transport.(*http.Transport).Clone()
+	tr.TLSClientConfig.ServerName = "example.com"
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, s.Listener.Addr().String())
+	}
+	return tr
+}
+
+func TestDo(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	var rejected int32
+	tr := newTestServer(t, func(w http.ResponseWriter, token, auth string) {
+		// 最初に発行したアクセストークンは失効したものとして扱う
+		if auth == "Bearer access-1" {
+			atomic.AddInt32(&rejected, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`)
+			return
+		}
+		mu.Lock()
+		attempts[token]++
+		n := attempts[token]
+		mu.Unlock()
+		switch {
+		case token == "gone":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND","details":[
+				{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`)
+		case token == "flaky" && n < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}`)
+		default:
+			fmt.Fprintf(w, `{"name":"projects/test-project/messages/%s"}`, token)
+		}
+	})
+	multicast := &gcm.Message{RegIDs: []string{"ok", "gone", "flaky"}, Data: map[string]string{"k": "v"}}
+	empty := &gcm.Message{Data: map[string]string{"k": "v"}}
+	idle := &gcm.Message{To: "idle", DelayWhileIdle: true}
+	req := &gcm.Request{
+		URL:        testBaseURL + "/v1/",
+		Credential: &gcm.Credential{ServiceAccount: newTestServiceAccount(t)},
+		Messages: []*gcm.Message{
+			multicast,
+			{To: "/topics/news", Notification: map[string]string{"title": "hello"}},
+			empty,
+			idle,
+		},
+	}
+	c := &Client{Transport: tr, MinBackoff: time.Millisecond}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 2 {
+		t.Fatalf("FailedMessages = %d; want 2", len(resp.FailedMessages))
+	}
+	f := resp.FailedMessages[0]
+	if f.Message != empty || f.ErrorString == "" {
+		t.Errorf("FailedMessages[0] = %+v; want no target error", f)
+	}
+	f = resp.FailedMessages[1]
+	if f.Error == nil || f.Message != multicast {
+		t.Fatalf("FailedMessages[1] = %+v; want APIError", f)
+	}
+	if f.Error.Message != multicast || f.Error.To != "gone" || f.Error.ErrorCode() != "UNREGISTERED" || !f.Error.BadRegID() {
+		t.Errorf("Error = %+v; want UNREGISTERED for gone", f.Error)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Message != idle || resp.Warnings[0].Report.Untranslatable[0].Field != "DelayWhileIdle" {
+		t.Errorf("Warnings = %+v; want DelayWhileIdle for idle", resp.Warnings)
+	}
+	if n := attempts["flaky"]; n != 3 {
+		t.Errorf("attempts[flaky] = %d; want 3", n)
+	}
+	if n := attempts["/topics/news"]; n != 1 {
+		t.Errorf("attempts[/topics/news] = %d; want 1", n)
+	}
+	if rejected == 0 {
+		t.Errorf("expired access token was not used")
+	}
+}
+
+func TestDoRetryExhausted(t *testing.T) {
+	var attempts int32
+	tr := newTestServer(t, func(w http.ResponseWriter, token, auth string) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"code":429,"message":"Quota exceeded.","status":"RESOURCE_EXHAUSTED","details":[
+			{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"QUOTA_EXCEEDED"}]}}`)
+	})
+	req := &gcm.Request{
+		URL:        testBaseURL + "/v1/projects/test-project/messages:send",
+		Credential: &gcm.Credential{ServiceAccount: newTestServiceAccount(t)},
+		Messages:   []*gcm.Message{{To: "token"}},
+		BandWidth:  100,
+	}
+	c := &Client{Transport: tr, MaxRetries: 2, MinBackoff: time.Millisecond}
+	resp, err := c.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(resp.FailedMessages) != 1 || resp.FailedMessages[0].Error == nil {
+		t.Fatalf("FailedMessages = %+v; want 1 APIError", resp.FailedMessages)
+	}
+	if e := resp.FailedMessages[0].Error; e.ErrorCode() != "QUOTA_EXCEEDED" || !e.Temporary() {
+		t.Errorf("Error = %v; want temporary QUOTA_EXCEEDED", e)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d; want 3", attempts)
+	}
+}
+
+func TestDoUnsupportedURL(t *testing.T) {
+	for _, u := range []string{
+		"https://fcm.googleapis.com/fcm/send",
+		"https://gcm-http.googleapis.com/gcm/send",
+	} {
+		req := &gcm.Request{URL: u, Credential: &gcm.Credential{}}
+		var c Client
+		if _, err := c.Do(context.Background(), req); err == nil || !strings.Contains(err.Error(), "v1") {
+			t.Errorf("Do(%s) = %v; want unsupported URL", u, err)
+		}
+	}
+}
+
+func TestSendURL(t *testing.T) {
+	tab := []struct {
+		URL  string
+		Want string
+	}{
+		{URL: "https://fcm.googleapis.com/v1/", Want: "https://fcm.googleapis.com/v1/projects/p/messages:send"},
+		{URL: "https://fcm.googleapis.com/v1/projects/q/messages:send", Want: "https://fcm.googleapis.com/v1/projects/q/messages:send"},
+	}
+	for _, v := range tab {
+		if s := sendURL(v.URL, "p"); s != v.Want {
+			t.Errorf("sendURL(%s) = %s; want %s", v.URL, s, v.Want)
+		}
+	}
+}
+
+func TestTransportReuse(t *testing.T) {
+	var c Client
+	defer c.CloseIdleConnections()
+	cred := &gcm.Credential{ServiceAccount: "a"}
+	if a, b := c.transport(cred), c.transport(&gcm.Credential{ServiceAccount: "b"}); a != b {
+		t.Errorf("transport returned different Transports for the same settings")
+	}
+	if a, b := c.transport(cred), c.transport(&gcm.Credential{InsecureSkipVerify: true}); a == b {
+		t.Errorf("transport shared a Transport with InsecureSkipVerify")
+	}
+}
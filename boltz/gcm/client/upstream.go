@@ -0,0 +1,283 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+	"github.com/BoltzEngine/apis/boltz/internal/throttle"
+)
+
+var (
+	errNotXMPP         = errors.New("gcm: upstream messages require FCM XMPP API")
+	errUnknownUpstream = errors.New("gcm: unknown upstream message")
+)
+
+// Receiverは端末からCCS経由で送られる上りメッセージを受け取る。
+// CONNECTION_DRAININGを受け取るか接続が切れた場合は、新しい接続を開いて受け取り続ける。
+type Receiver struct {
+	client *Client
+	addr   string
+	cred   *gcm.Credential
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	ch     chan *gcm.UpstreamMessage
+	ready  chan struct{} // queueにメッセージを追加したことをpumpへ知らせる
+	pumped chan struct{} // pumpが終わったら閉じる
+
+	mu     sync.Mutex
+	closed bool
+	cur    *xmppConn
+	conns  []*xmppConn
+	owners map[string]upstreamOwner // message_idごとの受け取った接続
+	queue  []*gcm.UpstreamMessage   // まだMessagesへ渡していないメッセージ
+}
+
+// upstreamOwnerは上りメッセージをackするための接続と送信元をあらわす。
+type upstreamOwner struct {
+	conn *xmppConn
+	from string
+	// trueならconnはccsSessionの送信用の接続で、ackするまでretainしている
+	retained bool
+}
+
+// ReceiveはaddrのCCSへ接続して、上りメッセージの受け取りを始める。
+// 受け取ったメッセージはMessagesから読み出し、処理を終えたらAckを呼ぶこと。
+// Ackを呼ばなかったメッセージはCCSが再送するので、同じIDのメッセージを複数回受け取ることがある。
+// CCSは送信用の接続にも上りメッセージを送るので、cを使ったDoで受け取った同じSenderIDの上りメッセージも
+// Messagesへ渡す。そのメッセージをackするまで、Doの送信用の接続はDoが終わっても閉じない。
+// 同じSenderIDで複数のReceiverを使う場合は、最後に作ったReceiverへ渡す。
+// ctxが終了するかCloseを呼ぶと、すべての接続を閉じてMessagesを閉じる。
+func (c *Client) Receive(ctx context.Context, addr string, cred *gcm.Credential) (*Receiver, error) {
+	if gcm.ProtocolVersion(addr) != gcm.FcmXmppApi {
+		return nil, errNotXMPP
+	}
+	if cred == nil {
+		return nil, errMissingCredential
+	}
+	r := &Receiver{
+		client: c,
+		addr:   addr,
+		cred:   cred,
+		done:   make(chan struct{}),
+		ch:     make(chan *gcm.UpstreamMessage),
+		ready:  make(chan struct{}, 1),
+		pumped: make(chan struct{}),
+		owners: make(map[string]upstreamOwner),
+	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	x, err := c.dialXMPP(r.ctx, addr, cred, r.deliver)
+	if err != nil {
+		r.cancel()
+		return nil, err
+	}
+	r.setConn(x)
+	c.register(r)
+	go r.pump()
+	go r.run()
+	return r, nil
+}
+
+// Messagesは受け取った上りメッセージを返すチャネルを返す。
+func (r *Receiver) Messages() <-chan *gcm.UpstreamMessage {
+	return r.ch
+}
+
+// Ackはidの上りメッセージをCCSへackする。受け取った接続が既に切れていればエラーを返す。
+// その場合CCSはメッセージを再送する。
+func (r *Receiver) Ack(id string) error {
+	r.mu.Lock()
+	v, ok := r.owners[id]
+	delete(r.owners, id)
+	r.mu.Unlock()
+	if !ok {
+		return errUnknownUpstream
+	}
+	err := errStreamClosed
+	if !v.conn.isClosed() {
+		err = v.conn.ack(v.from, id)
+	}
+	if v.retained && v.conn.unretain() {
+		v.conn.Close()
+	}
+	return err
+}
+
+// Closeはすべての接続を閉じて、受け取りを終える。
+func (r *Receiver) Close() error {
+	r.cancel()
+	<-r.done
+	return nil
+}
+
+// deliverはxで受け取った上りメッセージをキューに入れる。
+// xの読み込みを止めないように、Messagesへはpumpが渡す。
+// CCSはack待ちの上りメッセージを接続ごとに制限するので、キューは際限なく伸びない。
+func (r *Receiver) deliver(x *xmppConn, m *ccsMessage) {
+	r.enqueue(x, m, false)
+}
+
+// deliverRetainedはccsSessionの送信用の接続xで受け取った上りメッセージをキューに入れる。
+// Ackするまでxを閉じないようにretainして、Receiverを閉じる時にはxも閉じる。
+// ccsSessionが既にxを使い終えていれば、ackせずに捨ててCCSの再送に任せる。
+func (r *Receiver) deliverRetained(x *xmppConn, m *ccsMessage) {
+	r.enqueue(x, m, true)
+}
+
+func (r *Receiver) enqueue(x *xmppConn, m *ccsMessage, retain bool) {
+	r.mu.Lock()
+	if r.closed || retain && !x.retain() {
+		r.mu.Unlock()
+		return
+	}
+	if retain && !containsConn(r.conns, x) {
+		r.conns = append(r.conns, x)
+	}
+	r.owners[m.MessageID] = upstreamOwner{conn: x, from: m.From, retained: retain}
+	r.queue = append(r.queue, &gcm.UpstreamMessage{
+		ID:       m.MessageID,
+		From:     m.From,
+		Category: m.Category,
+		Data:     m.Data,
+	})
+	r.mu.Unlock()
+	select {
+	case r.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pumpはキューのメッセージを受け取った順にMessagesへ渡す。終了時にMessagesを閉じる。
+func (r *Receiver) pump() {
+	defer close(r.pumped)
+	defer close(r.ch)
+	for {
+		r.mu.Lock()
+		var m *gcm.UpstreamMessage
+		if len(r.queue) > 0 {
+			m = r.queue[0]
+			r.queue[0] = nil
+			r.queue = r.queue[1:]
+		}
+		r.mu.Unlock()
+		if m == nil {
+			select {
+			case <-r.ready:
+				continue
+			case <-r.ctx.Done():
+				return
+			}
+		}
+		select {
+		case r.ch <- m:
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// runは現在の接続が排出中になるか切れるたびに、新しい接続を開く。
+// 排出中の接続はCCSが閉じるまで残して、受け取ったメッセージをackできるようにする。
+func (r *Receiver) run() {
+	defer close(r.done)
+	defer r.shutdown()
+	_, minBackoff := r.client.retryPolicy()
+	for {
+		r.mu.Lock()
+		x := r.cur
+		r.mu.Unlock()
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-x.draining:
+		case <-x.done:
+		}
+		for n := 0; ; n++ {
+			x, err := r.client.dialXMPP(r.ctx, r.addr, r.cred, r.deliver)
+			if err == nil {
+				r.setConn(x)
+				break
+			}
+			if err := throttle.Sleep(r.ctx, expBackoff(minBackoff, n)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// setConnはxを現在の接続にして、切れた接続とそれで受け取ったメッセージを取り除く。
+func (r *Receiver) setConn(x *xmppConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conns := r.conns[:0]
+	for _, v := range r.conns {
+		if !v.isClosed() {
+			conns = append(conns, v)
+		}
+	}
+	for id, v := range r.owners {
+		if v.conn.isClosed() {
+			delete(r.owners, id)
+		}
+	}
+	r.conns = append(conns, x)
+	r.cur = x
+}
+
+func containsConn(a []*xmppConn, x *xmppConn) bool {
+	for _, v := range a {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Receiver) shutdown() {
+	r.client.unregister(r)
+	r.mu.Lock()
+	r.closed = true
+	conns := r.conns
+	r.conns = nil
+	r.mu.Unlock()
+	for _, x := range conns {
+		x.Close()
+		<-x.done
+	}
+	<-r.pumped
+}
+
+// registerはrをcred.SenderIDの上りメッセージの受け取り先にする。
+func (c *Client) register(r *Receiver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.receivers == nil {
+		c.receivers = make(map[string]*Receiver)
+	}
+	c.receivers[r.cred.SenderID] = r
+}
+
+func (c *Client) unregister(r *Receiver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.receivers[r.cred.SenderID] == r {
+		delete(c.receivers, r.cred.SenderID)
+	}
+}
+
+// routeUpstreamはsenderIDの送信用の接続で受け取った上りメッセージを、
+// Receiveで登録したReceiverへ渡す関数を返す。
+// Receiverが無ければackせずに捨てるので、CCSが後で再送する。
+func (c *Client) routeUpstream(senderID string) func(x *xmppConn, m *ccsMessage) {
+	return func(x *xmppConn, m *ccsMessage) {
+		c.mu.Lock()
+		r := c.receivers[senderID]
+		c.mu.Unlock()
+		if r != nil {
+			r.deliverRetained(x, m)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+)
+
+func TestReceive(t *testing.T) {
+	s := newCCSServer(t)
+	s.upstream = [][]*gcm.UpstreamMessage{
+		{
+			{ID: "up-1", From: "device-a", Category: "com.example.app", Data: map[string]string{"text": "hello"}},
+			{ID: "up-2", From: "device-b", Category: "com.example.app"},
+		},
+		{
+			{ID: "up-3", From: "device-a", Category: "com.example.app", Data: map[string]string{"text": "bye"}},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := &Client{Dial: s.dial, MinBackoff: time.Millisecond}
+	r, err := c.Receive(ctx, "fcm-xmpp.googleapis.com:5235", s.credential())
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	// Messagesを読まなくても、接続の読み込みは止まらずに排出を処理する
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		n := s.conns
+		s.mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("connections = %d before reading Messages; want 2", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var got []*gcm.UpstreamMessage
+	for m := range r.Messages() {
+		got = append(got, m)
+		if err := r.Ack(m.ID); err != nil {
+			t.Errorf("Ack(%s): %v", m.ID, err)
+		}
+		if len(got) == 3 {
+			break
+		}
+	}
+	var want []*gcm.UpstreamMessage
+	for _, a := range s.upstream {
+		want = append(want, a...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Messages = %+v; want %+v", got, want)
+	}
+
+	// ackがCCSに届くのを待つ
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		n := len(s.upAcked)
+		s.mu.Unlock()
+		if n == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, ok := <-r.Messages(); ok {
+		t.Errorf("Messages is not closed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 排出中の接続で受け取ったメッセージは、その接続でackする
+	wantAcked := map[string]int{"up-1": 0, "up-2": 0, "up-3": 1}
+	if !reflect.DeepEqual(s.upAcked, wantAcked) {
+		t.Errorf("acked = %v; want %v", s.upAcked, wantAcked)
+	}
+	if s.conns != 2 {
+		t.Errorf("connections = %d; want 2", s.conns)
+	}
+}
+
+func TestReceiveFromSendConnection(t *testing.T) {
+	s := newCCSServer(t)
+	// 最初の接続はReceiver、次の接続はDoが使う
+	want := &gcm.UpstreamMessage{ID: "up-1", From: "device-a", Category: "com.example.app"}
+	s.upstream = [][]*gcm.UpstreamMessage{nil, {want}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := &Client{Dial: s.dial, MinBackoff: time.Millisecond}
+	r, err := c.Receive(ctx, "fcm-xmpp.googleapis.com:5235", s.credential())
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	defer r.Close()
+	req := &gcm.Request{
+		URL:        "fcm-xmpp.googleapis.com:5235",
+		Credential: s.credential(),
+		Messages:   []*gcm.Message{{To: "token"}},
+	}
+	if _, err := c.Do(ctx, req); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	select {
+	case m := <-r.Messages():
+		if !reflect.DeepEqual(m, want) {
+			t.Errorf("Messages = %+v; want %+v", m, want)
+		}
+	case <-ctx.Done():
+		t.Fatalf("upstream message on the send connection was not delivered")
+	}
+	// Doが終わった後でも、受け取った送信用の接続でackできる
+	if err := r.Ack(want.ID); err != nil {
+		t.Fatalf("Ack(%s): %v", want.ID, err)
+	}
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		index, ok := s.upAcked[want.ID]
+		s.mu.Unlock()
+		if ok {
+			if index != 1 {
+				t.Errorf("%s was acked on connection %d; want 1", want.ID, index)
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatalf("%s was not acked", want.ID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReceiveNotXMPP(t *testing.T) {
+	var c Client
+	if _, err := c.Receive(context.Background(), "https://fcm.googleapis.com/v1/", &gcm.Credential{}); err != errNotXMPP {
+		t.Errorf("Receive = %v; want %v", err, errNotXMPP)
+	}
+}
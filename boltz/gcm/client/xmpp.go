@@ -0,0 +1,473 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/BoltzEngine/apis/boltz/gcm"
+)
+
+const (
+	// CCSのXMPPドメイン
+	ccsDomain = "fcm.googleapis.com"
+
+	nsStream = "http://etherx.jabber.org/streams"
+	nsSASL   = "urn:ietf:params:xml:ns:xmpp-sasl"
+	nsBind   = "urn:ietf:params:xml:ns:xmpp-bind"
+	nsGCM    = "google:mobile:data"
+
+	// ひとつの接続でack/nackを受け取っていないメッセージの最大数(CCSの制限)
+	maxPendingMessages = 100
+	// 接続を確立するまでの制限時間(ctxに期限が無い場合)
+	handshakeTimeout = 30 * time.Second
+)
+
+var (
+	errMissingServerKey = errors.New("gcm: XMPP API requires SenderID and ServerKey")
+	errStreamClosed     = errors.New("gcm: XMPP stream closed")
+	errConnDraining     = errors.New("gcm: XMPP connection is draining")
+	errNoPlainAuth      = errors.New("gcm: XMPP server does not support PLAIN mechanism")
+)
+
+// AuthErrorはCCSがSASL認証を拒否したことをあらわす。
+type AuthError struct {
+	Condition string // 例: not-authorized
+}
+
+func (e *AuthError) Error() string {
+	return "gcm: XMPP authentication failed: " + e.Condition
+}
+
+// ccsMessageはCCSとやりとりするgcm要素のJSONのうち、CCSから受け取る項目をあらわす。
+type ccsMessage struct {
+	MessageType      string `json:"message_type"`
+	MessageID        string `json:"message_id"`
+	From             string `json:"from"`
+	RegID            string `json:"registration_id"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	ControlType      string `json:"control_type"`
+
+	// 上りメッセージのみ
+	Category string            `json:"category"`
+	Data     map[string]string `json:"data"`
+}
+
+// xmppFeaturesはstream:features要素をあらわす。
+type xmppFeatures struct {
+	Mechanisms []string  `xml:"urn:ietf:params:xml:ns:xmpp-sasl mechanisms>mechanism"`
+	Bind       *struct{} `xml:"urn:ietf:params:xml:ns:xmpp-bind bind"`
+}
+
+// xmppStanzaはストリーム上で受け取るmessageとiqをあらわす。
+type xmppStanza struct {
+	XMLName xml.Name
+	Type    string `xml:"type,attr"`
+	ID      string `xml:"id,attr"`
+	GCM     string `xml:"google:mobile:data gcm"`
+	JID     string `xml:"urn:ietf:params:xml:ns:xmpp-bind bind>jid"`
+}
+
+// xmppConnはCCSへのひとつのXMPP接続をあらわす。
+// 同時にmaxPendingMessagesまでのメッセージをack/nackを待たずに送る。
+type xmppConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	d    *xml.Decoder
+	jid  string
+
+	wmu sync.Mutex // connへの書き込みを直列化する
+
+	window chan struct{} // ack/nackを待っているメッセージ数を制限する
+
+	mu      sync.Mutex
+	pending map[string]chan *ccsMessage // message_idごとの応答待ち
+	// 送信用の接続で受け取ってReceiverへ渡し、まだackしていない上りメッセージの数
+	// 0でなければccsSessionは接続を閉じずに、最後のackの後で閉じる。
+	retained int
+	released bool // ccsSessionが接続を使い終えた
+
+	// 上りメッセージを受け取った時に呼ぶ(nilなら無視してackも返さない)
+	upstream func(x *xmppConn, m *ccsMessage)
+
+	drainOnce sync.Once
+	draining  chan struct{} // CONNECTION_DRAININGを受け取ったら閉じる
+	done      chan struct{} // 接続が切れたら閉じる
+}
+
+// dialXMPPはaddrのCCSへ接続して、SenderIDとServerKeyで認証する。
+// upstreamがnilでなければ、上りメッセージを受け取るたびに呼ぶ。
+func (c *Client) dialXMPP(ctx context.Context, addr string, cred *gcm.Credential, upstream func(x *xmppConn, m *ccsMessage)) (*xmppConn, error) {
+	if cred.SenderID == "" || cred.ServerKey == "" {
+		return nil, errMissingServerKey
+	}
+	var conn net.Conn
+	var err error
+	if c.Dial != nil {
+		conn, err = c.Dial(ctx, addr)
+	} else {
+		conn, err = dialTLS(ctx, addr, cred)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(handshakeTimeout)
+	}
+	conn.SetDeadline(deadline)
+	x := &xmppConn{
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		window:   make(chan struct{}, maxPendingMessages),
+		pending:  make(map[string]chan *ccsMessage),
+		draining: make(chan struct{}),
+		done:     make(chan struct{}),
+		upstream: upstream,
+	}
+	if err := x.handshake(cred); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	go x.readLoop()
+	return x, nil
+}
+
+func dialTLS(ctx context.Context, addr string, cred *gcm.Credential) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	d := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: cred.InsecureSkipVerify,
+		},
+	}
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// handshakeはストリームを開始してSASL PLAINで認証し、リソースをバインドする。
+func (x *xmppConn) handshake(cred *gcm.Credential) error {
+	features, err := x.openStream()
+	if err != nil {
+		return err
+	}
+	if !contains(features.Mechanisms, "PLAIN") {
+		return errNoPlainAuth
+	}
+	user := cred.SenderID + "@" + ccsDomain
+	auth := base64.StdEncoding.EncodeToString([]byte("\x00" + user + "\x00" + cred.ServerKey))
+	if err := x.write(`<auth mechanism="PLAIN" xmlns="%s">%s</auth>`, nsSASL, auth); err != nil {
+		return err
+	}
+	start, err := x.next()
+	if err != nil {
+		return err
+	}
+	switch start.Name.Local {
+	case "success":
+		if err := x.d.Skip(); err != nil {
+			return err
+		}
+	case "failure":
+		var v struct {
+			Conditions []struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		}
+		if err := x.d.DecodeElement(&v, &start); err != nil {
+			return err
+		}
+		e := &AuthError{Condition: "unknown"}
+		if len(v.Conditions) > 0 {
+			e.Condition = v.Conditions[0].XMLName.Local
+		}
+		return e
+	default:
+		return fmt.Errorf("gcm: unexpected XMPP element %s", start.Name.Local)
+	}
+
+	// 認証に成功したらストリームを開始し直す
+	features, err = x.openStream()
+	if err != nil {
+		return err
+	}
+	if features.Bind == nil {
+		return errors.New("gcm: XMPP server does not offer resource binding")
+	}
+	if err := x.write(`<iq type="set" id="bind"><bind xmlns="%s"/></iq>`, nsBind); err != nil {
+		return err
+	}
+	var v xmppStanza
+	if err := x.decode(&v); err != nil {
+		return err
+	}
+	if v.XMLName.Local != "iq" || v.Type != "result" {
+		return fmt.Errorf("gcm: XMPP resource binding failed: %s %s", v.XMLName.Local, v.Type)
+	}
+	x.jid = v.JID
+	return nil
+}
+
+// openStreamはストリームを開始してサーバのstream:featuresを返す。
+func (x *xmppConn) openStream() (*xmppFeatures, error) {
+	err := x.write(`<stream:stream to="%s" version="1.0" xmlns="jabber:client" xmlns:stream="%s">`, ccsDomain, nsStream)
+	if err != nil {
+		return nil, err
+	}
+	// bufio.Readerはio.ByteReaderなので、Decoderが先読みしたバイトを失うことはない
+	x.d = xml.NewDecoder(x.r)
+	for {
+		t, err := x.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			if start.Name.Space != nsStream || start.Name.Local != "stream" {
+				return nil, fmt.Errorf("gcm: unexpected XMPP element %s", start.Name.Local)
+			}
+			break
+		}
+	}
+	start, err := x.next()
+	if err != nil {
+		return nil, err
+	}
+	if start.Name.Space != nsStream || start.Name.Local != "features" {
+		return nil, fmt.Errorf("gcm: unexpected XMPP element %s", start.Name.Local)
+	}
+	var v xmppFeatures
+	if err := x.d.DecodeElement(&v, &start); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// nextはストリームの次の子要素の開始タグを返す。
+// 空白(キープアライブ)は読み飛ばす。ストリームが閉じられたらerrStreamClosedを返す。
+func (x *xmppConn) next() (xml.StartElement, error) {
+	for {
+		t, err := x.d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			return t, nil
+		case xml.EndElement:
+			return xml.StartElement{}, errStreamClosed
+		}
+	}
+}
+
+// decodeはストリームの次の子要素をvに読み込む。
+func (x *xmppConn) decode(v *xmppStanza) error {
+	start, err := x.next()
+	if err != nil {
+		return err
+	}
+	if start.Name.Space == nsStream && start.Name.Local == "error" {
+		x.d.Skip()
+		return errStreamClosed
+	}
+	return x.d.DecodeElement(v, &start)
+}
+
+func (x *xmppConn) write(format string, args ...interface{}) error {
+	x.wmu.Lock()
+	defer x.wmu.Unlock()
+	_, err := fmt.Fprintf(x.conn, format, args...)
+	return err
+}
+
+// writeMessageはbodyをgcm要素としてCCSへ送る。
+func (x *xmppConn) writeMessage(body []byte) error {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, body); err != nil {
+		return err
+	}
+	return x.write(`<message id=""><gcm xmlns="%s">%s</gcm></message>`, nsGCM, buf.String())
+}
+
+// sendはbodyをmessage_idがidのメッセージとして送り、CCSからのack/nackを待つ。
+// 接続が排出中であればerrConnDrainingを、応答を受け取る前に接続が切れればerrStreamClosedを返す。
+// どちらの場合もCCSはメッセージを配信していない可能性があるので、別の接続で送り直すこと。
+func (x *xmppConn) send(ctx context.Context, id string, body []byte) (*ccsMessage, error) {
+	select {
+	case x.window <- struct{}{}:
+	case <-x.draining:
+		return nil, errConnDraining
+	case <-x.done:
+		return nil, errStreamClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-x.window }()
+	if x.isDraining() {
+		return nil, errConnDraining
+	}
+
+	ch := make(chan *ccsMessage, 1)
+	x.mu.Lock()
+	x.pending[id] = ch
+	x.mu.Unlock()
+	defer func() {
+		x.mu.Lock()
+		delete(x.pending, id)
+		x.mu.Unlock()
+	}()
+	if err := x.writeMessage(body); err != nil {
+		return nil, err
+	}
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-x.done:
+		select {
+		case v := <-ch:
+			return v, nil
+		default:
+			return nil, errStreamClosed
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ackは上りメッセージを受け取ったことをCCSへ伝える。
+func (x *xmppConn) ack(to, id string) error {
+	body, err := json.Marshal(map[string]string{
+		"to":           to,
+		"message_id":   id,
+		"message_type": "ack",
+	})
+	if err != nil {
+		return err
+	}
+	return x.writeMessage(body)
+}
+
+// readLoopは接続が切れるまでCCSからのメッセージを読み込み、応答待ちのsendへ渡す。
+func (x *xmppConn) readLoop() {
+	defer close(x.done)
+	for {
+		var v xmppStanza
+		if err := x.decode(&v); err != nil {
+			return
+		}
+		if v.XMLName.Local != "message" || v.GCM == "" {
+			continue
+		}
+		var m ccsMessage
+		if err := json.Unmarshal([]byte(v.GCM), &m); err != nil {
+			continue
+		}
+		x.dispatch(&m)
+	}
+}
+
+func (x *xmppConn) dispatch(m *ccsMessage) {
+	switch m.MessageType {
+	case "ack", "nack":
+		if m.Error == gcm.ConnectionDraining {
+			x.drain()
+		}
+		x.mu.Lock()
+		ch, ok := x.pending[m.MessageID]
+		delete(x.pending, m.MessageID)
+		x.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	case "control":
+		if m.ControlType == gcm.ConnectionDraining {
+			x.drain()
+		}
+	case "":
+		// message_typeが無いのは端末からの上りメッセージ
+		if x.upstream != nil {
+			x.upstream(x, m)
+		}
+	}
+}
+
+// drainは接続を排出中にする。以後この接続では新しいメッセージを送らず、
+// 送信済みのメッセージのack/nackだけを待つ。CCSが接続を閉じる。
+func (x *xmppConn) drain() {
+	x.drainOnce.Do(func() { close(x.draining) })
+}
+
+func (x *xmppConn) isDraining() bool {
+	select {
+	case <-x.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+func (x *xmppConn) isClosed() bool {
+	select {
+	case <-x.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Closeはストリームを終了して接続を閉じる。
+func (x *xmppConn) Close() error {
+	x.write(`</stream:stream>`)
+	return x.conn.Close()
+}
+
+// retainはReceiverへ渡した上りメッセージをackできるように、接続を閉じないようにする。
+// ccsSessionが既に接続を使い終えていればfalseを返す。
+func (x *xmppConn) retain() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.released {
+		return false
+	}
+	x.retained++
+	return true
+}
+
+// unretainはretainした上りメッセージのひとつをackし終えたことを記録する。
+// ccsSessionが使い終えていて、ack待ちの上りメッセージも無くなればtrueを返す。
+func (x *xmppConn) unretain() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.retained--
+	return x.retained == 0 && x.released
+}
+
+// releaseはccsSessionが接続を使い終えたことを記録する。
+// ack待ちの上りメッセージが無く、すぐに閉じてよければtrueを返す。
+func (x *xmppConn) release() bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.released = true
+	return x.retained == 0
+}
+
+func contains(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
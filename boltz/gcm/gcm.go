@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/url"
 	"strings"
+	"time"
 )
 
 const (
@@ -15,38 +16,65 @@ const (
 // APIErrorはFCM HTTP v1 APIのエラーをあらわす。
 type APIError struct {
 	Message *Message // オリジナルのメッセージ
+	// 失敗した宛先(MessageのRegIDsのひとつ、またはTo)
+	To string
 
 	Code        int
 	Status      string
 	Description string
+
+	// FcmError.errorCode(Statusより詳しい理由)
+	FcmErrorCode string
+	// APNsへの転送で失敗した場合のAPNsのエラー
+	ApnsError *ApnsError
+	// INVALID_ARGUMENTの場合に、不正だったメッセージのフィールド
+	FieldViolations []*FieldViolation
+	// Retry-Afterヘッダで指定された再送までの待ち時間(指定が無ければ0)
+	RetryAfter time.Duration
 }
 
+// ErrorCodeはFCMのエラーコードを返す。
+// FcmError.errorCodeが既知のコードであればそれを、そうでなければStatusを返す。
+// どちらも未知のエラーコードの場合はINTERNALとして扱う。
 func (e *APIError) ErrorCode() string {
-	switch e.Status {
+	switch {
+	case isKnownErrorCode(e.FcmErrorCode):
+		return e.FcmErrorCode
+	case isKnownErrorCode(e.Status):
+		return e.Status
+	default:
+		return "INTERNAL"
+	}
+}
+
+func isKnownErrorCode(code string) bool {
+	switch code {
 	case "NOT_FOUND",
 		"PERMISSION_DENIED",
 		"RESOURCE_EXHAUSTED",
 		"UNAUTHENTICATED",
 		"APNS_AUTH_ERROR",
+		"THIRD_PARTY_AUTH_ERROR",
 		"INTERNAL",
 		"INVALID_ARGUMENT",
 		"SENDER_ID_MISMATCH",
 		"QUOTA_EXCEEDED",
 		"UNAVAILABLE",
 		"UNREGISTERED":
-		return e.Status
+		return true
 	default:
-		return "INTERNAL"
+		return false
 	}
 }
 
 var (
 	invalidParamCodes = map[string]struct{}{
-		"PERMISSION_DENIED":  struct{}{},
-		"UNAUTHENTICATED":    struct{}{},
-		"APNS_AUTH_ERROR":    struct{}{},
-		"INVALID_ARGUMENT":   struct{}{},
-		"SENDER_ID_MISMATCH": struct{}{},
+		"PERMISSION_DENIED":      struct{}{},
+		"UNAUTHENTICATED":        struct{}{},
+		"APNS_AUTH_ERROR":        struct{}{},
+		"THIRD_PARTY_AUTH_ERROR": struct{}{},
+		"INVALID_ARGUMENT":       struct{}{},
+		"SENDER_ID_MISMATCH":     struct{}{},
 	}
 
 	invalidTokenCodes = map[string]struct{}{
@@ -164,6 +192,14 @@ func (p *Signal) CausedMessage() *Message {
 	return p.Message
 }
 
+// UpstreamMessageは端末からCCS経由でサーバへ送られた上りメッセージをあらわす。
+type UpstreamMessage struct {
+	ID       string            `json:"message_id"`
+	From     string            `json:"from"`     // 送信元端末のRegID
+	Category string            `json:"category"` // 送信元アプリのパッケージ名
+	Data     map[string]string `json:"data,omitempty"`
+}
+
 // Message represents request message to send to FCM
 type Message struct {
 	ID               string            `json:"message_id,omitempty"`
@@ -261,6 +297,8 @@ type Response struct {
 	// 送信失敗したメッセージと理由。
 	// すべて成功した場合は空の配列。
 	FailedMessages []*FailedMessage
+	// 送信はしたが、FCM HTTP v1 APIへ翻訳できなかった項目を無視したメッセージ。
+	Warnings []*TranslationWarning
 }
 
 const (
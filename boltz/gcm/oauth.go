@@ -0,0 +1,255 @@
+package gcm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenURIはサービスアカウントにtoken_uriが無い場合に使うトークンエンドポイント。
+	DefaultTokenURI = "https://oauth2.googleapis.com/token"
+	// MessagingScopeはFCM HTTP v1 APIで必要なOAuth2スコープ。
+	MessagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// TokenExpiryDeltaはアクセストークンを有効期限のどれだけ前に更新するか。
+	TokenExpiryDelta = 5 * time.Minute
+
+	// JWTアサーションの有効期間(Googleが許す最大値)
+	assertionLifetime = time.Hour
+	// トークンエンドポイントのレスポンスとして読み込む最大バイト数
+	maxTokenResponse = 64 * 1024
+)
+
+var (
+	ErrMissingServiceAccount = errors.New("gcm: missing service account")
+	ErrInvalidPrivateKey     = errors.New("gcm: private key must be PEM encoded RSA key")
+)
+
+// ServiceAccountはFirebaseプロジェクトのservice-account.jsonの内容をあらわす。
+type ServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccountはservice-account.jsonの内容を読み込む。
+func ParseServiceAccount(s string) (*ServiceAccount, error) {
+	if s == "" {
+		return nil, ErrMissingServiceAccount
+	}
+	var a ServiceAccount
+	if err := json.Unmarshal([]byte(s), &a); err != nil {
+		return nil, fmt.Errorf("gcm: invalid service account: %v", err)
+	}
+	if a.ClientEmail == "" || a.PrivateKey == "" {
+		return nil, errors.New("gcm: service account requires client_email and private_key")
+	}
+	return &a, nil
+}
+
+// TokenErrorはトークンエンドポイントが返したエラーをあらわす。
+type TokenError struct {
+	StatusCode  int
+	Code        string // error
+	Description string // error_description
+}
+
+func (e *TokenError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("gcm: token endpoint returned %d %s", e.StatusCode, e.Code)
+	}
+	return fmt.Sprintf("gcm: token endpoint returned %d %s: %s", e.StatusCode, e.Code, e.Description)
+}
+
+// TokenSourceはCredential.ServiceAccountからFCM HTTP v1 API用のアクセストークンを取得する。
+// 取得したトークンは有効期限のTokenExpiryDelta前までキャッシュされる。
+// 複数のゴルーチンから同時に使っても安全。
+type TokenSource struct {
+	account  *ServiceAccount
+	key      *rsa.PrivateKey
+	tokenURI string
+	client   *http.Client
+
+	now func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewTokenSourceはcred.ServiceAccountを使うTokenSourceを返す。
+// サービスアカウントのtoken_uriへアサーションを送ってアクセストークンを得る。
+// clientがnilならcred.InsecureSkipVerifyに従うhttp.Clientを使う。
+func NewTokenSource(cred *Credential, client *http.Client) (*TokenSource, error) {
+	account, err := ParseServiceAccount(cred.ServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseRSAPrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	tokenURI := account.TokenURI
+	if tokenURI == "" {
+		tokenURI = DefaultTokenURI
+	}
+	if client == nil {
+		client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: cred.InsecureSkipVerify},
+			},
+		}
+	}
+	return &TokenSource{
+		account:  account,
+		key:      key,
+		tokenURI: tokenURI,
+		client:   client,
+		now:      time.Now,
+	}, nil
+}
+
+// ProjectIDはサービスアカウントのFirebaseプロジェクトIDを返す。
+func (s *TokenSource) ProjectID() string {
+	return s.account.ProjectID
+}
+
+// Tokenはキャッシュしているアクセストークンを返す。
+// キャッシュが無いか有効期限が近い場合はトークンエンドポイントから取得し直す。
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.token != "" && now.Add(TokenExpiryDelta).Before(s.expiry) {
+		return s.token, nil
+	}
+	return s.refresh(ctx, now)
+}
+
+// ExpireはFCMがUNAUTHENTICATEDを返した場合に呼ぶ。
+// tokenが現在のトークンであればキャッシュを破棄して、次のTokenで取得し直させる。
+func (s *TokenSource) Expire(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token == s.token {
+		s.token = ""
+		s.expiry = time.Time{}
+	}
+}
+
+// tokenResponseはトークンエンドポイントのレスポンスボディをあらわす。
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *TokenSource) refresh(ctx context.Context, now time.Time) (string, error) {
+	assertion, err := s.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	r, err := http.NewRequest(http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTokenResponse))
+	if err != nil {
+		return "", err
+	}
+	var v tokenResponse
+	if err := json.Unmarshal(body, &v); err != nil && resp.StatusCode/100 == 2 {
+		return "", fmt.Errorf("gcm: invalid token response: %v", err)
+	}
+	if resp.StatusCode/100 != 2 || v.AccessToken == "" {
+		return "", &TokenError{StatusCode: resp.StatusCode, Code: v.Error, Description: v.ErrorDescription}
+	}
+	s.token = v.AccessToken
+	s.expiry = now.Add(time.Duration(v.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// assertionはトークンエンドポイントへ送るRS256で署名したJWTを返す。
+func (s *TokenSource) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": s.account.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   s.account.ClientEmail,
+		"scope": MessagingScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	v := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(v))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return v + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKeyはPEMエンコードされたRSA秘密鍵を読み込む。
+// サービスアカウントのPKCS#8形式とPKCS#1形式のどちらも受け付ける。
+func parseRSAPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	v, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := v.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidPrivateKey
+	}
+	return key, nil
+}
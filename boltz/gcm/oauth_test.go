@@ -0,0 +1,147 @@
+package gcm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServiceAccountはtokenURIを使うサービスアカウントのJSONと、その公開鍵を返す。
+func newTestServiceAccount(t *testing.T, tokenURI string) (string, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(&ServiceAccount{
+		Type:         "service_account",
+		ProjectID:    "example-project",
+		PrivateKeyID: "KEYID",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "fcm@example-project.iam.gserviceaccount.com",
+		TokenURI:     tokenURI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), &key.PublicKey
+}
+
+// newTestTokenServerはアサーションを検査してaccess-N形式のトークンを返すトークンエンドポイントを返す。
+func newTestTokenServer(t *testing.T, key **rsa.PublicKey, count *int32) *httptest.Server {
+	t.Helper()
+	var s *httptest.Server
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if v := r.PostForm.Get("grant_type"); v != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("grant_type = %q", v)
+		}
+		a := strings.Split(r.PostForm.Get("assertion"), ".")
+		if len(a) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"malformed assertion"}`)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(a[2])
+		sum := sha256.Sum256([]byte(a[0] + "." + a[1]))
+		if err := rsa.VerifyPKCS1v15(*key, crypto.SHA256, sum[:], sig); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Invalid JWT Signature."}`)
+			return
+		}
+		b, _ := base64.RawURLEncoding.DecodeString(a[1])
+		var claims map[string]interface{}
+		json.Unmarshal(b, &claims)
+		if claims["aud"] != s.URL || claims["scope"] != MessagingScope {
+			t.Errorf("claims = %v", claims)
+		}
+		n := atomic.AddInt32(count, 1)
+		fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","expires_in":3599}`, n)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestTokenSource(t *testing.T) {
+	var (
+		key   *rsa.PublicKey
+		count int32
+	)
+	s := newTestTokenServer(t, &key, &count)
+	account, pub := newTestServiceAccount(t, s.URL)
+	key = pub
+
+	ts, err := NewTokenSource(&Credential{ServiceAccount: account}, nil)
+	if err != nil {
+		t.Fatalf("NewTokenSource: %v", err)
+	}
+	if ts.ProjectID() != "example-project" {
+		t.Errorf("ProjectID = %q", ts.ProjectID())
+	}
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	ts.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	token, err := ts.Token(ctx)
+	if err != nil || token != "access-1" {
+		t.Fatalf("Token = %q, %v; want access-1", token, err)
+	}
+	now = now.Add(50 * time.Minute)
+	if token, _ := ts.Token(ctx); token != "access-1" {
+		t.Errorf("Token(cached) = %q; want access-1", token)
+	}
+	now = now.Add(5 * time.Minute)
+	if token, _ := ts.Token(ctx); token != "access-2" {
+		t.Errorf("Token(near expiry) = %q; want access-2", token)
+	}
+
+	ts.Expire("access-1") // 古いトークンでは破棄しない
+	if token, _ := ts.Token(ctx); token != "access-2" {
+		t.Errorf("Token after stale Expire = %q; want access-2", token)
+	}
+	ts.Expire("access-2")
+	if token, _ := ts.Token(ctx); token != "access-3" {
+		t.Errorf("Token after Expire = %q; want access-3", token)
+	}
+
+	// 署名を検証できない場合はTokenErrorを返す
+	other, _ := newTestServiceAccount(t, s.URL)
+	ts, err = NewTokenSource(&Credential{ServiceAccount: other}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ts.Token(ctx)
+	if e, ok := err.(*TokenError); !ok || e.StatusCode != http.StatusBadRequest || e.Code != "invalid_grant" {
+		t.Errorf("Token = %v; want invalid_grant TokenError", err)
+	}
+}
+
+func TestParseServiceAccount(t *testing.T) {
+	for _, s := range []string{"", "{", `{"client_email":"a@example.com"}`} {
+		if _, err := ParseServiceAccount(s); err == nil {
+			t.Errorf("ParseServiceAccount(%q) = nil; want an error", s)
+		}
+	}
+	if _, err := NewTokenSource(&Credential{ServiceAccount: `{"client_email":"a","private_key":"x"}`}, nil); err != ErrInvalidPrivateKey {
+		t.Errorf("NewTokenSource(bad key) = %v; want %v", err, ErrInvalidPrivateKey)
+	}
+}
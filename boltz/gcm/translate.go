@@ -0,0 +1,294 @@
+package gcm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// レガシーAPIのトピック宛先の接頭辞
+const legacyTopicPrefix = "/topics/"
+
+// UntranslatableはV1Messageへ翻訳できなかったレガシーMessageの項目をあらわす。
+type Untranslatable struct {
+	// 翻訳できなかったフィールド(例: "Priority"、"Notification.badge")
+	Field string
+	// 翻訳できなかった理由
+	Description string
+}
+
+func (u *Untranslatable) String() string {
+	return u.Field + ": " + u.Description
+}
+
+// TranslationReportはTranslateV1が翻訳できなかった項目の一覧をあらわす。
+type TranslationReport struct {
+	Untranslatable []*Untranslatable
+}
+
+// Emptyはすべての項目を翻訳できた場合にtrueを返す。
+func (r *TranslationReport) Empty() bool {
+	return len(r.Untranslatable) == 0
+}
+
+func (r *TranslationReport) String() string {
+	a := make([]string, len(r.Untranslatable))
+	for i, u := range r.Untranslatable {
+		a[i] = u.String()
+	}
+	return strings.Join(a, "; ")
+}
+
+// TranslationWarningは翻訳できなかった項目を無視して送信したメッセージをあらわす。
+type TranslationWarning struct {
+	// 無視した項目
+	Report *TranslationReport
+	// 対象のメッセージ
+	Message *Message
+}
+
+func (r *TranslationReport) add(field, format string, args ...interface{}) {
+	r.Untranslatable = append(r.Untranslatable, &Untranslatable{
+		Field:       field,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// TranslateV1はレガシーHTTP/XMPP APIのmをFCM HTTP v1 APIのリクエストに翻訳する。
+// RegIDsとToの宛先ごとにひとつのV1Requestを返す。
+// 同じmから作ったV1Requestはマップなどを共有するので、変更する場合は複製すること。
+//
+// TimeToLiveとPriorityはandroidのttlとpriority、APNsのapns-expirationとapns-priorityへ、
+// ContentAvailableとMutableContentはAPNsのaps.content-availableとaps.mutable-contentへ翻訳する。
+// nowはapns-expirationの基準に使う。
+// 翻訳できなかった項目は無視して、TranslationReportで返す。
+func TranslateV1(m *Message, now time.Time) ([]*V1Request, *TranslationReport) {
+	t := &translator{
+		report: &TranslationReport{},
+		msg:    V1Message{Data: m.Data},
+	}
+	t.translateOptions(m, now)
+	t.translateNotification(m.Notification)
+	t.finish()
+
+	var reqs []*V1Request
+	newRequest := func() *V1Message {
+		v := t.msg
+		reqs = append(reqs, &V1Request{ValidateOnly: m.DryRun, Message: &v})
+		return &v
+	}
+	for _, id := range m.RegIDs {
+		newRequest().Token = id
+	}
+	switch {
+	case strings.HasPrefix(m.To, legacyTopicPrefix):
+		newRequest().Topic = strings.TrimPrefix(m.To, legacyTopicPrefix)
+	case m.To != "":
+		newRequest().Token = m.To
+	}
+	if len(reqs) == 0 {
+		t.report.add("RegIDs", "message has no registration IDs")
+	}
+	return reqs, t.report
+}
+
+// translatorはひとつのMessageを翻訳する間の状態をあらわす。
+type translator struct {
+	report *TranslationReport
+	msg    V1Message
+
+	android      AndroidConfig
+	notification AndroidNotification
+	headers      map[string]string
+	aps          map[string]interface{}
+	alert        map[string]interface{}
+}
+
+func (t *translator) setHeader(key, value string) {
+	if t.headers == nil {
+		t.headers = make(map[string]string)
+	}
+	t.headers[key] = value
+}
+
+func (t *translator) setAps(key string, value interface{}) {
+	if t.aps == nil {
+		t.aps = make(map[string]interface{})
+	}
+	t.aps[key] = value
+}
+
+func (t *translator) setAlert(key string, value interface{}) {
+	if t.alert == nil {
+		t.alert = make(map[string]interface{})
+	}
+	t.alert[key] = value
+}
+
+func (t *translator) translateOptions(m *Message, now time.Time) {
+	if m.CollapseKey != "" {
+		t.android.CollapseKey = m.CollapseKey
+		t.setHeader("apns-collapse-id", m.CollapseKey)
+	}
+	if m.TimeToLive > 0 {
+		ttl := time.Duration(m.TimeToLive) * time.Second
+		t.android.TTL = NewDuration(ttl)
+		t.setHeader("apns-expiration", strconv.FormatInt(now.Add(ttl).Unix(), 10))
+	} else if m.TimeToLive < 0 {
+		t.report.add("TimeToLive", "negative time to live %d", m.TimeToLive)
+	}
+	switch m.Priority {
+	case "":
+	case "high":
+		t.android.Priority = AndroidPriorityHigh
+		t.setHeader("apns-priority", "10")
+	case "normal":
+		t.android.Priority = AndroidPriorityNormal
+		t.setHeader("apns-priority", "5")
+	default:
+		t.report.add("Priority", "unknown priority %q", m.Priority)
+	}
+	if m.ContentAvailable {
+		t.setAps("content-available", 1)
+	}
+	if m.MutableContent {
+		t.setAps("mutable-content", 1)
+	}
+	if m.DelayWhileIdle {
+		t.report.add("DelayWhileIdle", "not supported by HTTP v1 API")
+	}
+	if m.ID != "" {
+		t.report.add("ID", "message_id is for XMPP API only")
+	}
+}
+
+// translateNotificationはレガシーAPIのnotificationのキーを翻訳する。
+func (t *translator) translateNotification(n map[string]string) {
+	var common Notification
+	keys := make([]string, 0, len(n))
+	for key := range n {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := n[key]
+		switch key {
+		case "title":
+			common.Title = value
+		case "body":
+			common.Body = value
+		case "image":
+			common.Image = value
+		case "android_channel_id":
+			t.notification.ChannelID = value
+		case "icon":
+			t.notification.Icon = value
+		case "color":
+			t.notification.Color = value
+		case "tag":
+			t.notification.Tag = value
+		case "click_action":
+			t.notification.ClickAction = value
+			t.setAps("category", value)
+		case "sound":
+			t.notification.Sound = value
+			t.setAps("sound", value)
+		case "badge":
+			badge, err := strconv.Atoi(value)
+			if err != nil {
+				t.report.add("Notification.badge", "badge must be an integer: %q", value)
+				continue
+			}
+			t.setAps("badge", badge)
+		case "subtitle":
+			t.setAlert("subtitle", value)
+		case "body_loc_key":
+			t.notification.BodyLocKey = value
+			t.setAlert("loc-key", value)
+		case "title_loc_key":
+			t.notification.TitleLocKey = value
+			t.setAlert("title-loc-key", value)
+		case "body_loc_args", "title_loc_args":
+			var args []string
+			if err := json.Unmarshal([]byte(value), &args); err != nil {
+				t.report.add("Notification."+key, "must be a JSON array of strings: %v", err)
+				continue
+			}
+			if key == "body_loc_args" {
+				t.notification.BodyLocArgs = args
+				t.setAlert("loc-args", args)
+			} else {
+				t.notification.TitleLocArgs = args
+				t.setAlert("title-loc-args", args)
+			}
+		default:
+			t.report.add("Notification."+key, "unknown notification key")
+		}
+	}
+	if common != (Notification{}) {
+		t.msg.Notification = &common
+		if t.alert != nil {
+			// apsのalertはmessage.notificationより優先されるので、タイトルと本文も含める
+			if common.Title != "" {
+				t.alert["title"] = common.Title
+			}
+			if common.Body != "" {
+				t.alert["body"] = common.Body
+			}
+		}
+	}
+}
+
+// finishは集めた値から各プラットフォームの設定を組み立てる。
+func (t *translator) finish() {
+	if !isZeroAndroidNotification(&t.notification) {
+		t.android.Notification = &t.notification
+	}
+	if !isZeroAndroidConfig(&t.android) {
+		t.msg.Android = &t.android
+	}
+	if t.alert != nil {
+		t.setAps("alert", t.alert)
+	}
+	if t.isBackground() {
+		// 表示を伴わないcontent-availableはbackgroundとして優先度5で送らなければならない
+		if t.headers["apns-priority"] == "10" {
+			t.report.add("Priority", "background notification is sent with apns-priority 5")
+		}
+		t.setHeader("apns-push-type", "background")
+		t.setHeader("apns-priority", "5")
+	}
+	if t.headers != nil || t.aps != nil {
+		t.msg.APNs = &ApnsConfig{Headers: t.headers}
+		if t.aps != nil {
+			t.msg.APNs.Payload = map[string]interface{}{"aps": t.aps}
+		}
+	}
+}
+
+// isBackgroundはAPNsへの通知がcontent-availableだけで表示を伴わない場合にtrueを返す。
+func (t *translator) isBackground() bool {
+	if t.aps["content-available"] == nil || t.msg.Notification != nil {
+		return false
+	}
+	for _, key := range []string{"alert", "badge", "sound"} {
+		if _, ok := t.aps[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeroAndroidNotification(n *AndroidNotification) bool {
+	return n.Title == "" && n.Body == "" && n.Icon == "" && n.Color == "" && n.Sound == "" &&
+		n.Tag == "" && n.ClickAction == "" && n.BodyLocKey == "" && len(n.BodyLocArgs) == 0 &&
+		n.TitleLocKey == "" && len(n.TitleLocArgs) == 0 && n.ChannelID == "" && n.Image == ""
+}
+
+func isZeroAndroidConfig(c *AndroidConfig) bool {
+	return c.CollapseKey == "" && c.Priority == "" && c.TTL == nil && c.RestrictedPackageName == "" &&
+		len(c.Data) == 0 && c.Notification == nil && c.FCMOptions == nil && !c.DirectBootOK
+}
@@ -0,0 +1,81 @@
+package gcm
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTranslateV1(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	m := &Message{
+		RegIDs:         []string{"reg1", "reg2"},
+		Data:           map[string]string{"k": "v"},
+		CollapseKey:    "score",
+		TimeToLive:     3600,
+		Priority:       "high",
+		MutableContent: true,
+		DryRun:         true,
+		Notification: map[string]string{
+			"title":              "title",
+			"body":               "body",
+			"android_channel_id": "news",
+			"sound":              "default",
+			"badge":              "3",
+			"unknown":            "x",
+		},
+	}
+	reqs, report := TranslateV1(m, now)
+	if len(reqs) != 2 {
+		t.Fatalf("len(requests) = %d; want 2", len(reqs))
+	}
+	if reqs[0].Message.Token != "reg1" || reqs[1].Message.Token != "reg2" || !reqs[0].ValidateOnly {
+		t.Errorf("requests = %+v, %+v", reqs[0], reqs[1])
+	}
+	b, err := json.Marshal(reqs[0].Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"data":{"k":"v"},"notification":{"title":"title","body":"body"},` +
+		`"android":{"collapse_key":"score","priority":"HIGH","ttl":"3600s","notification":{"sound":"default","channel_id":"news"}},` +
+		`"apns":{"headers":{"apns-collapse-id":"score","apns-expiration":"1585746000","apns-priority":"10"},` +
+		`"payload":{"aps":{"badge":3,"mutable-content":1,"sound":"default"}}},"token":"reg1"}`
+	if string(b) != want {
+		t.Errorf("Message = %s; want %s", b, want)
+	}
+	if len(report.Untranslatable) != 1 || report.Untranslatable[0].Field != "Notification.unknown" {
+		t.Errorf("report = %v; want Notification.unknown", report)
+	}
+}
+
+func TestTranslateV1Background(t *testing.T) {
+	m := &Message{
+		To:               "/topics/news",
+		Priority:         "high",
+		ContentAvailable: true,
+		DelayWhileIdle:   true,
+	}
+	reqs, report := TranslateV1(m, time.Now())
+	if len(reqs) != 1 || reqs[0].Message.Topic != "news" {
+		t.Fatalf("requests = %+v; want topic news", reqs)
+	}
+	b, err := json.Marshal(reqs[0].Message.APNs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"headers":{"apns-priority":"5","apns-push-type":"background"},"payload":{"aps":{"content-available":1}}}`
+	if string(b) != want {
+		t.Errorf("APNs = %s; want %s", b, want)
+	}
+	if reqs[0].Message.Android.Priority != AndroidPriorityHigh {
+		t.Errorf("Android.Priority = %q; want %q", reqs[0].Message.Android.Priority, AndroidPriorityHigh)
+	}
+	if len(report.Untranslatable) != 2 {
+		t.Errorf("report = %v; want Priority and DelayWhileIdle", report)
+	}
+
+	reqs, report = TranslateV1(&Message{Priority: "urgent"}, time.Now())
+	if len(reqs) != 0 || len(report.Untranslatable) != 2 {
+		t.Errorf("TranslateV1(no target) = %d requests, report %v", len(reqs), report)
+	}
+}
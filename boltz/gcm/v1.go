@@ -0,0 +1,201 @@
+package gcm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FCM HTTP v1 APIのmessages:sendのURL
+const V1SendURLFormat = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+
+// V1SendURLはprojectIDのFirebaseプロジェクトへ送信するURLを返す。
+func V1SendURL(projectID string) string {
+	return fmt.Sprintf(V1SendURLFormat, projectID)
+}
+
+// AndroidConfig.Priorityの値
+const (
+	AndroidPriorityNormal = "NORMAL"
+	AndroidPriorityHigh   = "HIGH"
+)
+
+var (
+	errMissingTarget  = errors.New("gcm: message requires one of token, topic or condition")
+	errMultipleTarget = errors.New("gcm: message must have only one of token, topic or condition")
+)
+
+// V1RequestはFCM HTTP v1 APIのmessages:sendのリクエストボディをあらわす。
+type V1Request struct {
+	// trueの場合、FCMはメッセージを検査するだけで配信しない
+	ValidateOnly bool       `json:"validate_only,omitempty"`
+	Message      *V1Message `json:"message"`
+}
+
+// V1MessageはFCM HTTP v1 APIのメッセージをあらわす。
+// Token、Topic、Conditionのどれか1つだけをセットする。
+type V1Message struct {
+	// FCMが割り当てた識別子(レスポンスのみ; projects/*/messages/{message_id})
+	Name string `json:"name,omitempty"`
+
+	Data         map[string]string `json:"data,omitempty"`
+	Notification *Notification     `json:"notification,omitempty"`
+	Android      *AndroidConfig    `json:"android,omitempty"`
+	Webpush      *WebpushConfig    `json:"webpush,omitempty"`
+	APNs         *ApnsConfig       `json:"apns,omitempty"`
+	FCMOptions   *FCMOptions       `json:"fcm_options,omitempty"`
+
+	Token     string `json:"token,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// Validateはmの送信先が正しく指定されているかを検査する。
+func (m *V1Message) Validate() error {
+	n := 0
+	for _, s := range []string{m.Token, m.Topic, m.Condition} {
+		if s != "" {
+			n++
+		}
+	}
+	switch {
+	case n == 0:
+		return errMissingTarget
+	case n > 1:
+		return errMultipleTarget
+	}
+	return nil
+}
+
+// Notificationはすべてのプラットフォームで共通の通知をあらわす。
+type Notification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	// 通知に表示する画像のURL
+	Image string `json:"image,omitempty"`
+}
+
+// FCMOptionsはすべてのプラットフォームで共通のFCMのオプションをあらわす。
+type FCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
+
+// AndroidConfigはAndroid端末へ送る場合の固有の設定をあらわす。
+type AndroidConfig struct {
+	CollapseKey string `json:"collapse_key,omitempty"`
+	// AndroidPriorityNormalまたはAndroidPriorityHigh
+	Priority string `json:"priority,omitempty"`
+	// メッセージを保存する期間(nilならFCMのデフォルトの4週間)
+	TTL                   *Duration            `json:"ttl,omitempty"`
+	RestrictedPackageName string               `json:"restricted_package_name,omitempty"`
+	Data                  map[string]string    `json:"data,omitempty"`
+	Notification          *AndroidNotification `json:"notification,omitempty"`
+	FCMOptions            *AndroidFCMOptions   `json:"fcm_options,omitempty"`
+	// trueの場合、端末がロック解除される前(ダイレクトブートモード)でも配信する
+	DirectBootOK bool `json:"direct_boot_ok,omitempty"`
+}
+
+// AndroidNotificationはAndroid端末に表示する通知をあらわす。
+type AndroidNotification struct {
+	Title        string   `json:"title,omitempty"`
+	Body         string   `json:"body,omitempty"`
+	Icon         string   `json:"icon,omitempty"`
+	Color        string   `json:"color,omitempty"`
+	Sound        string   `json:"sound,omitempty"`
+	Tag          string   `json:"tag,omitempty"`
+	ClickAction  string   `json:"click_action,omitempty"`
+	BodyLocKey   string   `json:"body_loc_key,omitempty"`
+	BodyLocArgs  []string `json:"body_loc_args,omitempty"`
+	TitleLocKey  string   `json:"title_loc_key,omitempty"`
+	TitleLocArgs []string `json:"title_loc_args,omitempty"`
+	ChannelID    string   `json:"channel_id,omitempty"`
+	Image        string   `json:"image,omitempty"`
+}
+
+// AndroidFCMOptionsはAndroid端末へ送る場合のFCMのオプションをあらわす。
+type AndroidFCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
+
+// ApnsConfigはAPNs経由でApple端末へ送る場合の固有の設定をあらわす。
+type ApnsConfig struct {
+	// APNsへ送るHTTPヘッダ(apns-priority、apns-expiration、apns-push-typeなど)
+	Headers map[string]string `json:"headers,omitempty"`
+	// APNsへ送るJSONペイロード(apsを含む)
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+	FCMOptions *ApnsFCMOptions        `json:"fcm_options,omitempty"`
+}
+
+// ApnsFCMOptionsはAPNsへ送る場合のFCMのオプションをあらわす。
+type ApnsFCMOptions struct {
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+	// 通知に表示する画像のURL
+	Image string `json:"image,omitempty"`
+}
+
+// WebpushConfigはWebPushで送る場合の固有の設定をあらわす。
+type WebpushConfig struct {
+	// WebPushのHTTPヘッダ(TTL、Urgencyなど)
+	Headers map[string]string `json:"headers,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+	// Web Notification APIのオプション
+	Notification map[string]interface{} `json:"notification,omitempty"`
+	FCMOptions   *WebpushFCMOptions     `json:"fcm_options,omitempty"`
+}
+
+// WebpushFCMOptionsはWebPushで送る場合のFCMのオプションをあらわす。
+type WebpushFCMOptions struct {
+	// 通知をクリックした時に開くURL(HTTPSのみ)
+	Link           string `json:"link,omitempty"`
+	AnalyticsLabel string `json:"analytics_label,omitempty"`
+}
+
+// Durationはprotobufのgoogle.protobuf.DurationのJSON表現("3.5s")で符号化される期間をあらわす。
+type Duration time.Duration
+
+// NewDurationはdのDurationへのポインタを返す。
+func NewDuration(d time.Duration) *Duration {
+	v := Duration(d)
+	return &v
+}
+
+func (d Duration) String() string {
+	sec := int64(time.Duration(d) / time.Second)
+	nsec := int64(time.Duration(d) % time.Second)
+	if nsec == 0 {
+		return strconv.FormatInt(sec, 10) + "s"
+	}
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		sec, nsec = -sec, -nsec
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+	return fmt.Sprintf("%s%d.%ss", sign, sec, frac)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(s, "s") {
+		return fmt.Errorf("gcm: invalid duration %q", s)
+	}
+	if _, err := strconv.ParseFloat(s[:len(s)-1], 64); err != nil {
+		return fmt.Errorf("gcm: invalid duration %q", s)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("gcm: invalid duration %q", s)
+	}
+	*d = Duration(v)
+	return nil
+}
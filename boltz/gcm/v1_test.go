@@ -0,0 +1,97 @@
+package gcm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestV1MessageJSON(t *testing.T) {
+	req := &V1Request{
+		Message: &V1Message{
+			Token:        "token1",
+			Data:         map[string]string{"k": "v"},
+			Notification: &Notification{Title: "title", Body: "body", Image: "https://example.com/a.png"},
+			Android: &AndroidConfig{
+				Priority:     AndroidPriorityHigh,
+				TTL:          NewDuration(3500 * time.Millisecond),
+				Notification: &AndroidNotification{ChannelID: "news"},
+				DirectBootOK: true,
+			},
+			APNs: &ApnsConfig{
+				Headers: map[string]string{"apns-priority": "5"},
+				Payload: map[string]interface{}{"aps": map[string]interface{}{"content-available": 1}},
+			},
+			Webpush:    &WebpushConfig{FCMOptions: &WebpushFCMOptions{Link: "https://example.com/"}},
+			FCMOptions: &FCMOptions{AnalyticsLabel: "label"},
+		},
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":{"data":{"k":"v"},` +
+		`"notification":{"title":"title","body":"body","image":"https://example.com/a.png"},` +
+		`"android":{"priority":"HIGH","ttl":"3.5s","notification":{"channel_id":"news"},"direct_boot_ok":true},` +
+		`"webpush":{"fcm_options":{"link":"https://example.com/"}},` +
+		`"apns":{"headers":{"apns-priority":"5"},"payload":{"aps":{"content-available":1}}},` +
+		`"fcm_options":{"analytics_label":"label"},"token":"token1"}}`
+	if string(b) != want {
+		t.Errorf("Marshal = %s; want %s", b, want)
+	}
+
+	var v V1Request
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v.Message.Android, req.Message.Android) {
+		t.Errorf("Unmarshal android = %+v; want %+v", v.Message.Android, req.Message.Android)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tab := []struct {
+		D time.Duration
+		S string
+	}{
+		{D: 0, S: "0s"},
+		{D: 4 * 7 * 24 * time.Hour, S: "2419200s"},
+		{D: 1500 * time.Millisecond, S: "1.5s"},
+		{D: time.Nanosecond, S: "0.000000001s"},
+		{D: -500 * time.Millisecond, S: "-0.5s"},
+	}
+	for _, v := range tab {
+		if s := Duration(v.D).String(); s != v.S {
+			t.Errorf("Duration(%v) = %s; want %s", v.D, s, v.S)
+		}
+		var d Duration
+		if err := json.Unmarshal([]byte(`"`+v.S+`"`), &d); err != nil || time.Duration(d) != v.D {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", v.S, time.Duration(d), err, v.D)
+		}
+	}
+	for _, s := range []string{`"10m"`, `"1m30s"`, `"s"`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(s), &d); err == nil {
+			t.Errorf("Unmarshal(%s) = nil; want an error", s)
+		}
+	}
+}
+
+func TestV1MessageValidate(t *testing.T) {
+	tab := []struct {
+		Message V1Message
+		OK      bool
+	}{
+		{Message: V1Message{Token: "t"}, OK: true},
+		{Message: V1Message{Topic: "news"}, OK: true},
+		{Message: V1Message{Condition: "'a' in topics"}, OK: true},
+		{Message: V1Message{}},
+		{Message: V1Message{Token: "t", Topic: "news"}},
+	}
+	for _, v := range tab {
+		if err := v.Message.Validate(); (err == nil) != v.OK {
+			t.Errorf("Validate(%+v) = %v; want ok=%v", v.Message, err, v.OK)
+		}
+	}
+}
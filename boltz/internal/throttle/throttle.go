@@ -0,0 +1,59 @@
+// Package throttle implements rate limiting and waiting helpers shared by the push clients.
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Pacerは1秒あたりの送信数を制限する。
+type Pacer struct {
+	ticker *time.Ticker
+}
+
+// NewPacerはbandWidth件/秒に制限するPacerを返す。
+// bandWidthが0以下なら無制限。
+func NewPacer(bandWidth int32) *Pacer {
+	if bandWidth <= 0 {
+		return &Pacer{}
+	}
+	d := time.Second / time.Duration(bandWidth)
+	if d <= 0 {
+		return &Pacer{}
+	}
+	return &Pacer{ticker: time.NewTicker(d)}
+}
+
+// Waitは次の送信が許可されるまで待つ。
+func (p *Pacer) Wait(ctx context.Context) error {
+	if p.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-p.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pacer) Stop() {
+	if p.ticker != nil {
+		p.ticker.Stop()
+	}
+}
+
+// Sleepはdだけ待つ。ctxが終了した場合はそのエラーを返す。
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
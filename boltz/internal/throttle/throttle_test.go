@@ -0,0 +1,44 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPacer(t *testing.T) {
+	p := NewPacer(0)
+	defer p.Stop()
+	if err := p.Wait(context.Background()); err != nil {
+		t.Errorf("Wait(unlimited) = %v", err)
+	}
+
+	p = NewPacer(100)
+	defer p.Stop()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait = %v", err)
+		}
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("3 Waits at 100/s took %v; want >= 20ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewPacer(1).Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait(canceled) = %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("Sleep(canceled) = %v; want %v", err, context.Canceled)
+	}
+}
@@ -20,6 +20,66 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// MessageStoragePolicy はチャネルに送信した通知の保存方法を表す。
+type MessageStoragePolicy int32
+
+const (
+	// 通知を保存しない
+	MessageStoragePolicy_NO_MESSAGE_STORED MessageStoragePolicy = 0
+	// 最新の通知をひとつだけ保存する
+	MessageStoragePolicy_MOST_RECENT_MESSAGE_STORED MessageStoragePolicy = 1
+)
+
+var MessageStoragePolicy_name = map[int32]string{
+	0: "NO_MESSAGE_STORED",
+	1: "MOST_RECENT_MESSAGE_STORED",
+}
+
+var MessageStoragePolicy_value = map[string]int32{
+	"NO_MESSAGE_STORED":          0,
+	"MOST_RECENT_MESSAGE_STORED": 1,
+}
+
+func (x MessageStoragePolicy) String() string {
+	return proto.EnumName(MessageStoragePolicy_name, int32(x))
+}
+
+func (MessageStoragePolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{0}
+}
+
+// ChannelOperation はブロードキャストチャネルに対する操作を表す。
+type ChannelOperation int32
+
+const (
+	ChannelOperation_LIST   ChannelOperation = 0
+	ChannelOperation_CREATE ChannelOperation = 1
+	ChannelOperation_READ   ChannelOperation = 2
+	ChannelOperation_DELETE ChannelOperation = 3
+)
+
+var ChannelOperation_name = map[int32]string{
+	0: "LIST",
+	1: "CREATE",
+	2: "READ",
+	3: "DELETE",
+}
+
+var ChannelOperation_value = map[string]int32{
+	"LIST":   0,
+	"CREATE": 1,
+	"READ":   2,
+	"DELETE": 3,
+}
+
+func (x ChannelOperation) String() string {
+	return proto.EnumName(ChannelOperation_name, int32(x))
+}
+
+func (ChannelOperation) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{1}
+}
+
 type Header struct {
 	// APNsサービスのアドレス
 	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
@@ -38,10 +98,16 @@ type Header struct {
 	// JWT認証時のトピック(e.g. Bundle ID)
 	Topic string `protobuf:"bytes,8,opt,name=topic,proto3" json:"topic,omitempty"`
 	// apns-push-type ('alert' / 'background'; default 'alert')
-	PushType             string   `protobuf:"bytes,9,opt,name=pushType,proto3" json:"pushType,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	PushType string `protobuf:"bytes,9,opt,name=pushType,proto3" json:"pushType,omitempty"`
+	// trueの場合、pushTypeが空なら各トークンのtopicとpayloadからapns-push-typeと優先度を推測する
+	// (content-availableのみならbackground/優先度5、alert/badge/soundを含めばalert、VoIPトピックならvoip)。
+	// 明示したpushTypeや優先度がpayloadと矛盾する場合はwarning Eventを返す。
+	InferPushType bool `protobuf:"varint,11,opt,name=inferPushType,proto3" json:"inferPushType,omitempty"`
+	// APNsへのHTTP/2接続の使い方(未指定ならスレーブのデフォルト)
+	Connection           *ConnectionPolicy `protobuf:"bytes,10,opt,name=connection,proto3" json:"connection,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Header) Reset()         { *m = Header{} }
@@ -132,29 +198,374 @@ func (m *Header) GetPushType() string {
 	return ""
 }
 
+func (m *Header) GetInferPushType() bool {
+	if m != nil {
+		return m.InferPushType
+	}
+	return false
+}
+
+func (m *Header) GetConnection() *ConnectionPolicy {
+	if m != nil {
+		return m.Connection
+	}
+	return nil
+}
+
+// ConnectionPolicy はスレーブがAPNsへのHTTP/2接続をどのようにプールするかを表す。
+// 0のフィールドはスレーブのデフォルトに従う。
+// プールは同じ接続先・資格情報・ポリシーのリクエスト間で共有される。
+type ConnectionPolicy struct {
+	// 同時に開くHTTP/2接続の数(default 1)
+	Connections int32 `protobuf:"varint,1,opt,name=connections,proto3" json:"connections,omitempty"`
+	// 1接続あたりの最大同時ストリーム数(default 100)
+	MaxConcurrentStreams int32 `protobuf:"varint,2,opt,name=maxConcurrentStreams,proto3" json:"maxConcurrentStreams,omitempty"`
+	// 受信が途絶えてからPINGで接続を確認するまでの時間(ナノ秒単位のtime.Duration; 0ならPINGしない)
+	PingInterval int64 `protobuf:"varint,3,opt,name=pingInterval,proto3" json:"pingInterval,omitempty"`
+	// PINGの応答を待つ時間(ナノ秒単位のtime.Duration; default 15秒)
+	PingTimeout int64 `protobuf:"varint,4,opt,name=pingTimeout,proto3" json:"pingTimeout,omitempty"`
+	// IdleTimeoutやShutdown、GOAWAYなどで接続が閉じられた場合に、再接続して再送する最大回数
+	MaxReconnects int32 `protobuf:"varint,5,opt,name=maxReconnects,proto3" json:"maxReconnects,omitempty"`
+	// 再接続するまでの待ち時間(ナノ秒単位のtime.Duration)
+	ReconnectDelay       int64    `protobuf:"varint,6,opt,name=reconnectDelay,proto3" json:"reconnectDelay,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConnectionPolicy) Reset()         { *m = ConnectionPolicy{} }
+func (m *ConnectionPolicy) String() string { return proto.CompactTextString(m) }
+func (*ConnectionPolicy) ProtoMessage()    {}
+func (*ConnectionPolicy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{1}
+}
+
+func (m *ConnectionPolicy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConnectionPolicy.Unmarshal(m, b)
+}
+func (m *ConnectionPolicy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConnectionPolicy.Marshal(b, m, deterministic)
+}
+func (m *ConnectionPolicy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConnectionPolicy.Merge(m, src)
+}
+func (m *ConnectionPolicy) XXX_Size() int {
+	return xxx_messageInfo_ConnectionPolicy.Size(m)
+}
+func (m *ConnectionPolicy) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConnectionPolicy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConnectionPolicy proto.InternalMessageInfo
+
+func (m *ConnectionPolicy) GetConnections() int32 {
+	if m != nil {
+		return m.Connections
+	}
+	return 0
+}
+
+func (m *ConnectionPolicy) GetMaxConcurrentStreams() int32 {
+	if m != nil {
+		return m.MaxConcurrentStreams
+	}
+	return 0
+}
+
+func (m *ConnectionPolicy) GetPingInterval() int64 {
+	if m != nil {
+		return m.PingInterval
+	}
+	return 0
+}
+
+func (m *ConnectionPolicy) GetPingTimeout() int64 {
+	if m != nil {
+		return m.PingTimeout
+	}
+	return 0
+}
+
+func (m *ConnectionPolicy) GetMaxReconnects() int32 {
+	if m != nil {
+		return m.MaxReconnects
+	}
+	return 0
+}
+
+func (m *ConnectionPolicy) GetReconnectDelay() int64 {
+	if m != nil {
+		return m.ReconnectDelay
+	}
+	return 0
+}
+
+// Channel はLive Activityのブロードキャストチャネルを表す。
+type Channel struct {
+	// apns-channel-id (作成時はAPNsが割り当てる)
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MessageStoragePolicy MessageStoragePolicy `protobuf:"varint,2,opt,name=messageStoragePolicy,proto3,enum=apns.MessageStoragePolicy" json:"messageStoragePolicy,omitempty"`
+	// チャネルで配信できるpush-type ('LiveActivity'; 作成時に空なら'LiveActivity')
+	PushType             string   `protobuf:"bytes,3,opt,name=pushType,proto3" json:"pushType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Channel) Reset()         { *m = Channel{} }
+func (m *Channel) String() string { return proto.CompactTextString(m) }
+func (*Channel) ProtoMessage()    {}
+func (*Channel) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{2}
+}
+
+func (m *Channel) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Channel.Unmarshal(m, b)
+}
+func (m *Channel) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Channel.Marshal(b, m, deterministic)
+}
+func (m *Channel) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Channel.Merge(m, src)
+}
+func (m *Channel) XXX_Size() int {
+	return xxx_messageInfo_Channel.Size(m)
+}
+func (m *Channel) XXX_DiscardUnknown() {
+	xxx_messageInfo_Channel.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Channel proto.InternalMessageInfo
+
+func (m *Channel) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Channel) GetMessageStoragePolicy() MessageStoragePolicy {
+	if m != nil {
+		return m.MessageStoragePolicy
+	}
+	return MessageStoragePolicy_NO_MESSAGE_STORED
+}
+
+func (m *Channel) GetPushType() string {
+	if m != nil {
+		return m.PushType
+	}
+	return ""
+}
+
+// ChannelRequest はブロードキャストチャネルの操作を表す。
+type ChannelRequest struct {
+	// APNs接続情報(addressはチャネル管理APIのアドレス)
+	Header *Header `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// チャネルを所有するアプリのBundle ID
+	BundleID  string           `protobuf:"bytes,2,opt,name=bundleID,proto3" json:"bundleID,omitempty"`
+	Operation ChannelOperation `protobuf:"varint,3,opt,name=operation,proto3,enum=apns.ChannelOperation" json:"operation,omitempty"`
+	// 作成・参照・削除するチャネル(LISTの場合は不要)
+	Channel              *Channel `protobuf:"bytes,4,opt,name=channel,proto3" json:"channel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChannelRequest) Reset()         { *m = ChannelRequest{} }
+func (m *ChannelRequest) String() string { return proto.CompactTextString(m) }
+func (*ChannelRequest) ProtoMessage()    {}
+func (*ChannelRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{3}
+}
+
+func (m *ChannelRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChannelRequest.Unmarshal(m, b)
+}
+func (m *ChannelRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChannelRequest.Marshal(b, m, deterministic)
+}
+func (m *ChannelRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChannelRequest.Merge(m, src)
+}
+func (m *ChannelRequest) XXX_Size() int {
+	return xxx_messageInfo_ChannelRequest.Size(m)
+}
+func (m *ChannelRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChannelRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChannelRequest proto.InternalMessageInfo
+
+func (m *ChannelRequest) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *ChannelRequest) GetBundleID() string {
+	if m != nil {
+		return m.BundleID
+	}
+	return ""
+}
+
+func (m *ChannelRequest) GetOperation() ChannelOperation {
+	if m != nil {
+		return m.Operation
+	}
+	return ChannelOperation_LIST
+}
+
+func (m *ChannelRequest) GetChannel() *Channel {
+	if m != nil {
+		return m.Channel
+	}
+	return nil
+}
+
+// ChannelResponse はチャネル操作の結果を表す。
+type ChannelResponse struct {
+	// 作成・参照したチャネル、またはチャネル一覧
+	Channels []*Channel `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+	// APNsとは関係のない場所で発生したエラー(例えば"no such host")
+	ErrorString string `protobuf:"bytes,2,opt,name=errorString,proto3" json:"errorString,omitempty"`
+	// 以下はAPNsがエラーを返した場合にセットされる
+	StatusCode           int32    `protobuf:"varint,3,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Timestamp            uint32   `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ApnsRequestID        string   `protobuf:"bytes,6,opt,name=apnsRequestID,proto3" json:"apnsRequestID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChannelResponse) Reset()         { *m = ChannelResponse{} }
+func (m *ChannelResponse) String() string { return proto.CompactTextString(m) }
+func (*ChannelResponse) ProtoMessage()    {}
+func (*ChannelResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9f97b0cb6762d65b, []int{4}
+}
+
+func (m *ChannelResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChannelResponse.Unmarshal(m, b)
+}
+func (m *ChannelResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChannelResponse.Marshal(b, m, deterministic)
+}
+func (m *ChannelResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChannelResponse.Merge(m, src)
+}
+func (m *ChannelResponse) XXX_Size() int {
+	return xxx_messageInfo_ChannelResponse.Size(m)
+}
+func (m *ChannelResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChannelResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChannelResponse proto.InternalMessageInfo
+
+func (m *ChannelResponse) GetChannels() []*Channel {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+func (m *ChannelResponse) GetErrorString() string {
+	if m != nil {
+		return m.ErrorString
+	}
+	return ""
+}
+
+func (m *ChannelResponse) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *ChannelResponse) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ChannelResponse) GetTimestamp() uint32 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ChannelResponse) GetApnsRequestID() string {
+	if m != nil {
+		return m.ApnsRequestID
+	}
+	return ""
+}
+
 func init() {
+	proto.RegisterEnum("apns.MessageStoragePolicy", MessageStoragePolicy_name, MessageStoragePolicy_value)
+	proto.RegisterEnum("apns.ChannelOperation", ChannelOperation_name, ChannelOperation_value)
 	proto.RegisterType((*Header)(nil), "apns.Header")
+	proto.RegisterType((*ConnectionPolicy)(nil), "apns.ConnectionPolicy")
+	proto.RegisterType((*Channel)(nil), "apns.Channel")
+	proto.RegisterType((*ChannelRequest)(nil), "apns.ChannelRequest")
+	proto.RegisterType((*ChannelResponse)(nil), "apns.ChannelResponse")
 }
 
 func init() { proto.RegisterFile("apns/apns.proto", fileDescriptor_9f97b0cb6762d65b) }
 
 var fileDescriptor_9f97b0cb6762d65b = []byte{
-	// 271 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0x86, 0x49, 0x6c, 0xd3, 0x74, 0x2c, 0x08, 0x4b, 0x91, 0xc5, 0x83, 0x84, 0xe2, 0x21, 0xa7,
-	0xe4, 0xe0, 0xd9, 0x83, 0xc1, 0x82, 0x22, 0x82, 0x44, 0xf1, 0xe0, 0x2d, 0xdd, 0x8c, 0xed, 0x92,
-	0x9a, 0x1d, 0x76, 0x37, 0xc2, 0xfa, 0x24, 0x3e, 0x83, 0x4f, 0x29, 0xd9, 0x6a, 0x89, 0xd0, 0xcb,
-	0xb2, 0xdf, 0xff, 0xcd, 0xc0, 0xf0, 0xc3, 0x49, 0x45, 0xad, 0xc9, 0xfb, 0x27, 0x23, 0xad, 0xac,
-	0x62, 0xa3, 0xfe, 0xbf, 0xf8, 0x0a, 0x21, 0xba, 0xc5, 0xaa, 0x46, 0xcd, 0x38, 0x4c, 0xaa, 0xba,
-	0xd6, 0x68, 0x0c, 0x0f, 0x92, 0x20, 0x9d, 0x96, 0x7f, 0xc8, 0x12, 0x38, 0x6e, 0xd0, 0x3d, 0x2e,
-	0x1f, 0x8a, 0xad, 0x12, 0x0d, 0x0f, 0x93, 0x20, 0x9d, 0x95, 0xc3, 0x88, 0x2d, 0x60, 0x26, 0x50,
-	0xdb, 0xfd, 0xc8, 0x91, 0x1f, 0xf9, 0x97, 0xb1, 0x0c, 0x98, 0x6c, 0x0d, 0x8a, 0x4e, 0xe3, 0x53,
-	0x23, 0xe9, 0x05, 0xb5, 0x7c, 0x73, 0x7c, 0x94, 0x04, 0x69, 0x5c, 0x1e, 0x30, 0xec, 0x14, 0x22,
-	0x69, 0x4c, 0x87, 0x9a, 0x8f, 0xfd, 0x39, 0xbf, 0xc4, 0xe6, 0x30, 0x6e, 0xd0, 0xdd, 0xdd, 0xf0,
-	0xc8, 0xc7, 0x3b, 0x60, 0xe7, 0x00, 0xa4, 0xe5, 0x47, 0x65, 0xf1, 0x1e, 0x1d, 0x9f, 0x78, 0x35,
-	0x48, 0xfa, 0x2d, 0xab, 0x48, 0x0a, 0x1e, 0xef, 0xb6, 0x3c, 0xb0, 0x33, 0x88, 0xa9, 0x33, 0x9b,
-	0x67, 0x47, 0xc8, 0xa7, 0x5e, 0xec, 0xb9, 0xb8, 0x7a, 0xbd, 0x58, 0x4b, 0xbb, 0xe9, 0x56, 0x99,
-	0x50, 0xef, 0x79, 0xa1, 0xb6, 0xf6, 0x73, 0xd9, 0xae, 0x65, 0x8b, 0x79, 0x45, 0xd2, 0xe4, 0x9a,
-	0x84, 0xaf, 0xf3, 0x3b, 0x9c, 0x0f, 0x5c, 0x56, 0x92, 0xc8, 0xae, 0xa9, 0x35, 0xab, 0xc8, 0xd7,
-	0x7c, 0xf9, 0x13, 0x00, 0x00, 0xff, 0xff, 0xd3, 0xd8, 0x5a, 0x20, 0x79, 0x01, 0x00, 0x00,
+	// 722 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x54, 0x41, 0x6f, 0xe2, 0x46,
+	0x14, 0x5e, 0xe3, 0x40, 0xe0, 0x91, 0x64, 0xe9, 0x88, 0xae, 0xac, 0xa8, 0x5a, 0x21, 0xb4, 0x6a,
+	0xd3, 0x3d, 0x10, 0x89, 0x56, 0xbd, 0xb5, 0x52, 0x02, 0x56, 0x1b, 0x75, 0x09, 0xd1, 0xd8, 0xea,
+	0xa1, 0x97, 0x68, 0x62, 0xde, 0xc2, 0x08, 0x3c, 0xe3, 0xce, 0x8c, 0x57, 0xa1, 0xf7, 0xfe, 0x99,
+	0xde, 0xfa, 0x77, 0xfa, 0x43, 0x7a, 0xea, 0xa1, 0x9a, 0xb1, 0x21, 0x36, 0xe5, 0x82, 0x78, 0xdf,
+	0xf7, 0xc6, 0xf3, 0xf9, 0xfb, 0xde, 0x33, 0xbc, 0x66, 0x99, 0xd0, 0xd7, 0xf6, 0x67, 0x94, 0x29,
+	0x69, 0x24, 0x39, 0xb1, 0xff, 0x87, 0xff, 0x34, 0xa0, 0xf5, 0x13, 0xb2, 0x05, 0x2a, 0x12, 0xc0,
+	0x29, 0x5b, 0x2c, 0x14, 0x6a, 0x1d, 0x78, 0x03, 0xef, 0xaa, 0x43, 0x77, 0x25, 0x19, 0x40, 0x77,
+	0x8d, 0xdb, 0x87, 0x70, 0x76, 0xbb, 0x91, 0xc9, 0x3a, 0x68, 0x0c, 0xbc, 0xab, 0x33, 0x5a, 0x85,
+	0xc8, 0x10, 0xce, 0x12, 0x54, 0x66, 0xdf, 0xe2, 0xbb, 0x96, 0x1a, 0x46, 0x46, 0x40, 0xb8, 0xd0,
+	0x98, 0xe4, 0x0a, 0xa3, 0x35, 0xcf, 0x7e, 0x41, 0xc5, 0x3f, 0x6e, 0x83, 0x93, 0x81, 0x77, 0xd5,
+	0xa6, 0x47, 0x18, 0xf2, 0x06, 0x5a, 0x5c, 0xeb, 0x1c, 0x55, 0xd0, 0x74, 0x72, 0xca, 0x8a, 0xf4,
+	0xa1, 0xb9, 0xc6, 0xed, 0xdd, 0x34, 0x68, 0x39, 0xb8, 0x28, 0xc8, 0x5b, 0x80, 0x4c, 0xf1, 0x4f,
+	0xcc, 0xe0, 0xcf, 0xb8, 0x0d, 0x4e, 0x1d, 0x55, 0x41, 0xec, 0x29, 0x23, 0x33, 0x9e, 0x04, 0xed,
+	0xe2, 0x94, 0x2b, 0xc8, 0x25, 0xb4, 0xb3, 0x5c, 0xaf, 0xe2, 0x6d, 0x86, 0x41, 0xc7, 0x11, 0xfb,
+	0x9a, 0xbc, 0x83, 0x73, 0x2e, 0x3e, 0xa2, 0x7a, 0xd8, 0x35, 0x74, 0x9d, 0xd4, 0x3a, 0x48, 0xbe,
+	0x03, 0x48, 0xa4, 0x10, 0x98, 0x18, 0x2e, 0x45, 0x00, 0x03, 0xef, 0xaa, 0x3b, 0x7e, 0x33, 0x72,
+	0x3e, 0x4f, 0xf6, 0xf8, 0x83, 0xdc, 0xf0, 0x64, 0x4b, 0x2b, 0x9d, 0xc3, 0x7f, 0x3d, 0xe8, 0x1d,
+	0x36, 0x58, 0xa3, 0x5f, 0x5a, 0x8a, 0x18, 0x9a, 0xb4, 0x0a, 0x91, 0x31, 0xf4, 0x53, 0xf6, 0x3c,
+	0x91, 0x22, 0xc9, 0x95, 0x42, 0x61, 0x22, 0xa3, 0x90, 0xa5, 0xda, 0x65, 0xd2, 0xa4, 0x47, 0x39,
+	0x1b, 0x4e, 0xc6, 0xc5, 0xf2, 0x4e, 0x18, 0x54, 0x9f, 0xd8, 0xc6, 0x85, 0xe3, 0xd3, 0x1a, 0x66,
+	0x6f, 0xb6, 0x75, 0xcc, 0x53, 0x94, 0xb9, 0x71, 0xa9, 0xf8, 0xb4, 0x0a, 0x59, 0x3b, 0x52, 0xf6,
+	0x4c, 0xb1, 0x54, 0xa3, 0x5d, 0x2a, 0x4d, 0x5a, 0x07, 0xc9, 0x97, 0x70, 0xa1, 0x76, 0xd5, 0x14,
+	0x37, 0x6c, 0xeb, 0x52, 0xf2, 0xe9, 0x01, 0x3a, 0xfc, 0xc3, 0x83, 0xd3, 0xc9, 0x8a, 0x09, 0x81,
+	0x1b, 0x72, 0x01, 0x0d, 0xbe, 0x28, 0x67, 0xae, 0xc1, 0x17, 0xe4, 0x1e, 0xfa, 0x29, 0x6a, 0xcd,
+	0x96, 0x18, 0x19, 0xa9, 0xd8, 0x12, 0x0b, 0x77, 0xdc, 0x3b, 0x5e, 0x8c, 0x2f, 0x0b, 0x73, 0x67,
+	0x47, 0x3a, 0xe8, 0xd1, 0x73, 0xb5, 0x90, 0xfd, 0x7a, 0xc8, 0xc3, 0xbf, 0x3c, 0xb8, 0x28, 0x75,
+	0x50, 0xfc, 0x2d, 0x47, 0x6d, 0x5f, 0xb4, 0xb5, 0x72, 0x1b, 0xe1, 0x24, 0x75, 0xc7, 0x67, 0xc5,
+	0x85, 0xc5, 0x96, 0xd0, 0x92, 0xb3, 0x0f, 0x7d, 0xca, 0xc5, 0x62, 0x83, 0x77, 0x53, 0x27, 0xac,
+	0x43, 0xf7, 0x35, 0xf9, 0x16, 0x3a, 0x32, 0x43, 0xc5, 0xdc, 0x48, 0xf8, 0x4e, 0xf5, 0x6e, 0x24,
+	0x8a, 0xab, 0xe6, 0x3b, 0x96, 0xbe, 0x34, 0x92, 0xaf, 0xe0, 0x34, 0x29, 0x68, 0x67, 0x7f, 0x77,
+	0x7c, 0x5e, 0x3b, 0x43, 0x77, 0xec, 0xf0, 0x6f, 0x0f, 0x5e, 0xef, 0x35, 0xeb, 0x4c, 0x0a, 0x8d,
+	0xe4, 0x6b, 0x68, 0x97, 0xb4, 0x1d, 0x1b, 0xff, 0xff, 0xa7, 0xf7, 0xb4, 0x8d, 0x1a, 0x95, 0x92,
+	0x2a, 0x32, 0x8a, 0x8b, 0x65, 0x29, 0xbe, 0x0a, 0xd9, 0x5d, 0xd2, 0x86, 0x99, 0x5c, 0x4f, 0xe4,
+	0xa2, 0xb0, 0xac, 0x49, 0x2b, 0x88, 0xdd, 0x4c, 0x85, 0x4c, 0x4b, 0xe1, 0x84, 0x76, 0x68, 0x59,
+	0x91, 0x2f, 0xa0, 0x63, 0x78, 0x8a, 0xda, 0xb0, 0x34, 0x73, 0xe3, 0x71, 0x4e, 0x5f, 0x00, 0x3b,
+	0x40, 0x56, 0x51, 0x69, 0xf3, 0x7e, 0x7f, 0xeb, 0xe0, 0xfb, 0x19, 0xf4, 0x8f, 0x45, 0x4b, 0x3e,
+	0x87, 0xcf, 0xee, 0xe7, 0x8f, 0xb3, 0x30, 0x8a, 0x6e, 0x7e, 0x0c, 0x1f, 0xa3, 0x78, 0x4e, 0xc3,
+	0x69, 0xef, 0x15, 0x79, 0x0b, 0x97, 0xb3, 0x79, 0x14, 0x3f, 0xd2, 0x70, 0x12, 0xde, 0xc7, 0x87,
+	0xbc, 0xf7, 0xfe, 0x07, 0xe8, 0x1d, 0x7a, 0x4e, 0xda, 0x70, 0xf2, 0xe1, 0x2e, 0x8a, 0x7b, 0xaf,
+	0x08, 0x40, 0x6b, 0x42, 0xc3, 0x9b, 0x38, 0xec, 0x79, 0x16, 0xa5, 0xe1, 0xcd, 0xb4, 0xd7, 0xb0,
+	0xe8, 0x34, 0xfc, 0x10, 0xc6, 0x61, 0xcf, 0xbf, 0xfd, 0xfe, 0xd7, 0x77, 0x4b, 0x6e, 0x56, 0xf9,
+	0xd3, 0x28, 0x91, 0xe9, 0xf5, 0xad, 0xdc, 0x98, 0xdf, 0x43, 0xb1, 0xe4, 0x02, 0xaf, 0x59, 0xc6,
+	0xf5, 0xb5, 0xca, 0x12, 0xf7, 0x4d, 0xfd, 0xb3, 0xd1, 0xaf, 0x70, 0x23, 0x9a, 0x25, 0xa3, 0x9b,
+	0x4c, 0xe8, 0xa7, 0x96, 0xfb, 0xd6, 0x7e, 0xf3, 0xdf, 0x00, 0x64, 0xd0, 0x31, 0xd9, 0x7e, 0x05,
+	0x00, 0x00,
 }
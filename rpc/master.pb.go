@@ -108,6 +108,7 @@ const (
 	Platform_ALT     Platform = 3
 	Platform_WEBPUSH Platform = 4
 	Platform_ADM     Platform = 5
+	Platform_PASS    Platform = 6
 )
 
 var Platform_name = map[int32]string{
@@ -118,6 +119,7 @@ var Platform_name = map[int32]string{
 	3: "ALT",
 	4: "WEBPUSH",
 	5: "ADM",
+	6: "PASS",
 }
 
 var Platform_value = map[string]int32{
@@ -128,6 +130,7 @@ var Platform_value = map[string]int32{
 	"ALT":     3,
 	"WEBPUSH": 4,
 	"ADM":     5,
+	"PASS":    6,
 }
 
 func (x Platform) String() string {
@@ -148,11 +151,15 @@ type Message struct {
 	WebpushHeader *webpush.Header `protobuf:"bytes,9,opt,name=webpushHeader,proto3" json:"webpushHeader,omitempty"`
 	// ADM固有の接続情報
 	AdmHeader *adm.Header `protobuf:"bytes,11,opt,name=admHeader,proto3" json:"admHeader,omitempty"`
+	// Walletのパス更新通知の接続情報(パスタイプ証明書とパスタイプ識別子のtopic)
+	PassHeader *apns.Header `protobuf:"bytes,15,opt,name=passHeader,proto3" json:"passHeader,omitempty"`
 	// 通知対象のデバイストークン
 	// APNsの場合は["1" + (hexエンコードされたトークン)]
 	// FCMの場合は["2" + (FCMの登録ID)]
 	// WebPushの場合は["4" + {"v":1,"endpoint":"(WebPushエンドポイント)","p256dh":"(ブラウザ公開鍵)","auth":"(WebPush乱数)"}],
 	// ADMの場合は["5" + (ADM登録ID)]
+	// Walletのパスの場合は["6" + (hexエンコードされたプッシュトークン)]
+	// (パスにはpayloadではなく空のペイロードを送る)
 	Tokens []string `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"`
 	// メッセージ配信の優先度
 	// Priority未定義値の場合は各プラットフォームのデフォルトを使う
@@ -161,6 +168,9 @@ type Message struct {
 	Expiration uint32 `protobuf:"varint,5,opt,name=expiration,proto3" json:"expiration,omitempty"`
 	// メッセージをまとめる文字列(URLセーフな文字を32文字まで)
 	CollapseKey string `protobuf:"bytes,12,opt,name=collapseKey,proto3" json:"collapseKey,omitempty"`
+	// 通知対象のAPNsブロードキャストチャネルID(apns-channel-id)
+	// Live Activityのブロードキャストプッシュはtokensの代わりにこちらを使う
+	Channels []string `protobuf:"bytes,13,rep,name=channels,proto3" json:"channels,omitempty"`
 	// APNsへ送るJSONペイロード(APNsトークンを含む場合は必須)
 	// JSONのフォーマットはAppleのドキュメントを参照すること
 	Payload string `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
@@ -171,7 +181,9 @@ type Message struct {
 	// フォーマットは受信アプリの仕様に依存する
 	Body string `protobuf:"bytes,10,opt,name=body,proto3" json:"body,omitempty"`
 	// 1秒あたりの通知数(0以下なら無制限)
-	BandWidth            int32    `protobuf:"varint,8,opt,name=bandWidth,proto3" json:"bandWidth,omitempty"`
+	BandWidth int32 `protobuf:"varint,8,opt,name=bandWidth,proto3" json:"bandWidth,omitempty"`
+	// trueの場合、APNsが受け付けたトークンごとにdelivered Eventを返す
+	ReportReceipts       bool     `protobuf:"varint,14,opt,name=reportReceipts,proto3" json:"reportReceipts,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -230,6 +242,13 @@ func (m *Message) GetAdmHeader() *adm.Header {
 	return nil
 }
 
+func (m *Message) GetPassHeader() *apns.Header {
+	if m != nil {
+		return m.PassHeader
+	}
+	return nil
+}
+
 func (m *Message) GetTokens() []string {
 	if m != nil {
 		return m.Tokens
@@ -258,6 +277,13 @@ func (m *Message) GetCollapseKey() string {
 	return ""
 }
 
+func (m *Message) GetChannels() []string {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
 func (m *Message) GetPayload() string {
 	if m != nil {
 		return m.Payload
@@ -286,12 +312,21 @@ func (m *Message) GetBandWidth() int32 {
 	return 0
 }
 
+func (m *Message) GetReportReceipts() bool {
+	if m != nil {
+		return m.ReportReceipts
+	}
+	return false
+}
+
 // DeliveryFailure は送信失敗したトークンと理由を表す。
 type DeliveryFailure struct {
 	Kind                 FailureKind `protobuf:"varint,1,opt,name=kind,proto3,enum=rpc.FailureKind" json:"kind,omitempty"`
 	Token                string      `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
 	Status               string      `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
 	Timestamp            uint32      `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ApnsID               string      `protobuf:"bytes,5,opt,name=apnsID,proto3" json:"apnsID,omitempty"`
+	ApnsUniqueID         string      `protobuf:"bytes,6,opt,name=apnsUniqueID,proto3" json:"apnsUniqueID,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
@@ -350,6 +385,205 @@ func (m *DeliveryFailure) GetTimestamp() uint32 {
 	return 0
 }
 
+func (m *DeliveryFailure) GetApnsID() string {
+	if m != nil {
+		return m.ApnsID
+	}
+	return ""
+}
+
+func (m *DeliveryFailure) GetApnsUniqueID() string {
+	if m != nil {
+		return m.ApnsUniqueID
+	}
+	return ""
+}
+
+// DeliveryReceipt はAPNsが受け付けたトークンと識別子を表す。
+type DeliveryReceipt struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ApnsID               string   `protobuf:"bytes,2,opt,name=apnsID,proto3" json:"apnsID,omitempty"`
+	ApnsUniqueID         string   `protobuf:"bytes,3,opt,name=apnsUniqueID,proto3" json:"apnsUniqueID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeliveryReceipt) Reset()         { *m = DeliveryReceipt{} }
+func (m *DeliveryReceipt) String() string { return proto.CompactTextString(m) }
+func (*DeliveryReceipt) ProtoMessage()    {}
+func (*DeliveryReceipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9c348dec43a6705, []int{2}
+}
+
+func (m *DeliveryReceipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeliveryReceipt.Unmarshal(m, b)
+}
+func (m *DeliveryReceipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeliveryReceipt.Marshal(b, m, deterministic)
+}
+func (m *DeliveryReceipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeliveryReceipt.Merge(m, src)
+}
+func (m *DeliveryReceipt) XXX_Size() int {
+	return xxx_messageInfo_DeliveryReceipt.Size(m)
+}
+func (m *DeliveryReceipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeliveryReceipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeliveryReceipt proto.InternalMessageInfo
+
+func (m *DeliveryReceipt) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *DeliveryReceipt) GetApnsID() string {
+	if m != nil {
+		return m.ApnsID
+	}
+	return ""
+}
+
+func (m *DeliveryReceipt) GetApnsUniqueID() string {
+	if m != nil {
+		return m.ApnsUniqueID
+	}
+	return ""
+}
+
+// DeliveryDeferral は送信頻度の制限により送信を見送ったトークンを表す。
+// 失敗ではないので、retryAfter以降に再送すればよい。
+type DeliveryDeferral struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	RetryAfter           uint32   `protobuf:"varint,2,opt,name=retryAfter,proto3" json:"retryAfter,omitempty"`
+	Coalesced            bool     `protobuf:"varint,3,opt,name=coalesced,proto3" json:"coalesced,omitempty"`
+	Status               string   `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeliveryDeferral) Reset()         { *m = DeliveryDeferral{} }
+func (m *DeliveryDeferral) String() string { return proto.CompactTextString(m) }
+func (*DeliveryDeferral) ProtoMessage()    {}
+func (*DeliveryDeferral) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9c348dec43a6705, []int{3}
+}
+
+func (m *DeliveryDeferral) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeliveryDeferral.Unmarshal(m, b)
+}
+func (m *DeliveryDeferral) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeliveryDeferral.Marshal(b, m, deterministic)
+}
+func (m *DeliveryDeferral) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeliveryDeferral.Merge(m, src)
+}
+func (m *DeliveryDeferral) XXX_Size() int {
+	return xxx_messageInfo_DeliveryDeferral.Size(m)
+}
+func (m *DeliveryDeferral) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeliveryDeferral.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeliveryDeferral proto.InternalMessageInfo
+
+func (m *DeliveryDeferral) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *DeliveryDeferral) GetRetryAfter() uint32 {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return 0
+}
+
+func (m *DeliveryDeferral) GetCoalesced() bool {
+	if m != nil {
+		return m.Coalesced
+	}
+	return false
+}
+
+func (m *DeliveryDeferral) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+// DeliveryWarning は送信はしたが確認が必要なトークンを表す。
+type DeliveryWarning struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Status               string   `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	PushType             string   `protobuf:"bytes,3,opt,name=pushType,proto3" json:"pushType,omitempty"`
+	InferredPushType     string   `protobuf:"bytes,4,opt,name=inferredPushType,proto3" json:"inferredPushType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeliveryWarning) Reset()         { *m = DeliveryWarning{} }
+func (m *DeliveryWarning) String() string { return proto.CompactTextString(m) }
+func (*DeliveryWarning) ProtoMessage()    {}
+func (*DeliveryWarning) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9c348dec43a6705, []int{4}
+}
+
+func (m *DeliveryWarning) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeliveryWarning.Unmarshal(m, b)
+}
+func (m *DeliveryWarning) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeliveryWarning.Marshal(b, m, deterministic)
+}
+func (m *DeliveryWarning) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeliveryWarning.Merge(m, src)
+}
+func (m *DeliveryWarning) XXX_Size() int {
+	return xxx_messageInfo_DeliveryWarning.Size(m)
+}
+func (m *DeliveryWarning) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeliveryWarning.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeliveryWarning proto.InternalMessageInfo
+
+func (m *DeliveryWarning) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *DeliveryWarning) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *DeliveryWarning) GetPushType() string {
+	if m != nil {
+		return m.PushType
+	}
+	return ""
+}
+
+func (m *DeliveryWarning) GetInferredPushType() string {
+	if m != nil {
+		return m.InferredPushType
+	}
+	return ""
+}
+
 // TokenRenewal はプラットフォーム側でのトークン変更を表す。
 type TokenRenewal struct {
 	// 送信に使ったトークン
@@ -365,7 +599,7 @@ func (m *TokenRenewal) Reset()         { *m = TokenRenewal{} }
 func (m *TokenRenewal) String() string { return proto.CompactTextString(m) }
 func (*TokenRenewal) ProtoMessage()    {}
 func (*TokenRenewal) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{2}
+	return fileDescriptor_f9c348dec43a6705, []int{5}
 }
 
 func (m *TokenRenewal) XXX_Unmarshal(b []byte) error {
@@ -409,6 +643,9 @@ type Event struct {
 	// Types that are valid to be assigned to Event:
 	//	*Event_Failed
 	//	*Event_Renewed
+	//	*Event_Delivered
+	//	*Event_Deferred
+	//	*Event_Warning
 	Event                isEvent_Event `protobuf_oneof:"event"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
@@ -419,7 +656,7 @@ func (m *Event) Reset()         { *m = Event{} }
 func (m *Event) String() string { return proto.CompactTextString(m) }
 func (*Event) ProtoMessage()    {}
 func (*Event) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{3}
+	return fileDescriptor_f9c348dec43a6705, []int{6}
 }
 
 func (m *Event) XXX_Unmarshal(b []byte) error {
@@ -459,10 +696,28 @@ type Event_Renewed struct {
 	Renewed *TokenRenewal `protobuf:"bytes,3,opt,name=renewed,proto3,oneof"`
 }
 
+type Event_Delivered struct {
+	Delivered *DeliveryReceipt `protobuf:"bytes,4,opt,name=delivered,proto3,oneof"`
+}
+
+type Event_Deferred struct {
+	Deferred *DeliveryDeferral `protobuf:"bytes,5,opt,name=deferred,proto3,oneof"`
+}
+
+type Event_Warning struct {
+	Warning *DeliveryWarning `protobuf:"bytes,6,opt,name=warning,proto3,oneof"`
+}
+
 func (*Event_Failed) isEvent_Event() {}
 
 func (*Event_Renewed) isEvent_Event() {}
 
+func (*Event_Delivered) isEvent_Event() {}
+
+func (*Event_Deferred) isEvent_Event() {}
+
+func (*Event_Warning) isEvent_Event() {}
+
 func (m *Event) GetEvent() isEvent_Event {
 	if m != nil {
 		return m.Event
@@ -484,14 +739,183 @@ func (m *Event) GetRenewed() *TokenRenewal {
 	return nil
 }
 
+func (m *Event) GetDelivered() *DeliveryReceipt {
+	if x, ok := m.GetEvent().(*Event_Delivered); ok {
+		return x.Delivered
+	}
+	return nil
+}
+
+func (m *Event) GetDeferred() *DeliveryDeferral {
+	if x, ok := m.GetEvent().(*Event_Deferred); ok {
+		return x.Deferred
+	}
+	return nil
+}
+
+func (m *Event) GetWarning() *DeliveryWarning {
+	if x, ok := m.GetEvent().(*Event_Warning); ok {
+		return x.Warning
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Event) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*Event_Failed)(nil),
 		(*Event_Renewed)(nil),
+		(*Event_Delivered)(nil),
+		(*Event_Deferred)(nil),
+		(*Event_Warning)(nil),
 	}
 }
 
+// UpstreamRequest はReceiveUpstreamでクライアントから送るメッセージを表す。
+type UpstreamRequest struct {
+	// Types that are valid to be assigned to Request:
+	//	*UpstreamRequest_Header
+	//	*UpstreamRequest_Ack
+	Request              isUpstreamRequest_Request `protobuf_oneof:"request"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *UpstreamRequest) Reset()         { *m = UpstreamRequest{} }
+func (m *UpstreamRequest) String() string { return proto.CompactTextString(m) }
+func (*UpstreamRequest) ProtoMessage()    {}
+func (*UpstreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9c348dec43a6705, []int{7}
+}
+
+func (m *UpstreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpstreamRequest.Unmarshal(m, b)
+}
+func (m *UpstreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpstreamRequest.Marshal(b, m, deterministic)
+}
+func (m *UpstreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpstreamRequest.Merge(m, src)
+}
+func (m *UpstreamRequest) XXX_Size() int {
+	return xxx_messageInfo_UpstreamRequest.Size(m)
+}
+func (m *UpstreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpstreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpstreamRequest proto.InternalMessageInfo
+
+type isUpstreamRequest_Request interface {
+	isUpstreamRequest_Request()
+}
+
+type UpstreamRequest_Header struct {
+	Header *gcm.Header `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type UpstreamRequest_Ack struct {
+	Ack string `protobuf:"bytes,2,opt,name=ack,proto3,oneof"`
+}
+
+func (*UpstreamRequest_Header) isUpstreamRequest_Request() {}
+
+func (*UpstreamRequest_Ack) isUpstreamRequest_Request() {}
+
+func (m *UpstreamRequest) GetRequest() isUpstreamRequest_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *UpstreamRequest) GetHeader() *gcm.Header {
+	if x, ok := m.GetRequest().(*UpstreamRequest_Header); ok {
+		return x.Header
+	}
+	return nil
+}
+
+func (m *UpstreamRequest) GetAck() string {
+	if x, ok := m.GetRequest().(*UpstreamRequest_Ack); ok {
+		return x.Ack
+	}
+	return ""
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*UpstreamRequest) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*UpstreamRequest_Header)(nil),
+		(*UpstreamRequest_Ack)(nil),
+	}
+}
+
+// UpstreamMessage は端末からサーバへ送られた上りメッセージを表す。
+type UpstreamMessage struct {
+	MessageId            string            `protobuf:"bytes,1,opt,name=messageId,proto3" json:"messageId,omitempty"`
+	From                 string            `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Category             string            `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	Data                 map[string]string `protobuf:"bytes,4,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *UpstreamMessage) Reset()         { *m = UpstreamMessage{} }
+func (m *UpstreamMessage) String() string { return proto.CompactTextString(m) }
+func (*UpstreamMessage) ProtoMessage()    {}
+func (*UpstreamMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f9c348dec43a6705, []int{8}
+}
+
+func (m *UpstreamMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpstreamMessage.Unmarshal(m, b)
+}
+func (m *UpstreamMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpstreamMessage.Marshal(b, m, deterministic)
+}
+func (m *UpstreamMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpstreamMessage.Merge(m, src)
+}
+func (m *UpstreamMessage) XXX_Size() int {
+	return xxx_messageInfo_UpstreamMessage.Size(m)
+}
+func (m *UpstreamMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpstreamMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpstreamMessage proto.InternalMessageInfo
+
+func (m *UpstreamMessage) GetMessageId() string {
+	if m != nil {
+		return m.MessageId
+	}
+	return ""
+}
+
+func (m *UpstreamMessage) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *UpstreamMessage) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *UpstreamMessage) GetData() map[string]string {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 type StatisticsQuery struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -502,7 +926,7 @@ func (m *StatisticsQuery) Reset()         { *m = StatisticsQuery{} }
 func (m *StatisticsQuery) String() string { return proto.CompactTextString(m) }
 func (*StatisticsQuery) ProtoMessage()    {}
 func (*StatisticsQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{4}
+	return fileDescriptor_f9c348dec43a6705, []int{9}
 }
 
 func (m *StatisticsQuery) XXX_Unmarshal(b []byte) error {
@@ -543,7 +967,7 @@ func (m *MasterStatistics) Reset()         { *m = MasterStatistics{} }
 func (m *MasterStatistics) String() string { return proto.CompactTextString(m) }
 func (*MasterStatistics) ProtoMessage()    {}
 func (*MasterStatistics) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{5}
+	return fileDescriptor_f9c348dec43a6705, []int{10}
 }
 
 func (m *MasterStatistics) XXX_Unmarshal(b []byte) error {
@@ -650,7 +1074,7 @@ func (m *SlaveStatistics) Reset()         { *m = SlaveStatistics{} }
 func (m *SlaveStatistics) String() string { return proto.CompactTextString(m) }
 func (*SlaveStatistics) ProtoMessage()    {}
 func (*SlaveStatistics) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{6}
+	return fileDescriptor_f9c348dec43a6705, []int{11}
 }
 
 func (m *SlaveStatistics) XXX_Unmarshal(b []byte) error {
@@ -789,7 +1213,7 @@ func (m *MemoryStatistics) Reset()         { *m = MemoryStatistics{} }
 func (m *MemoryStatistics) String() string { return proto.CompactTextString(m) }
 func (*MemoryStatistics) ProtoMessage()    {}
 func (*MemoryStatistics) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{7}
+	return fileDescriptor_f9c348dec43a6705, []int{12}
 }
 
 func (m *MemoryStatistics) XXX_Unmarshal(b []byte) error {
@@ -881,7 +1305,7 @@ func (m *UnavailableTokenEvent) Reset()         { *m = UnavailableTokenEvent{} }
 func (m *UnavailableTokenEvent) String() string { return proto.CompactTextString(m) }
 func (*UnavailableTokenEvent) ProtoMessage()    {}
 func (*UnavailableTokenEvent) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f9c348dec43a6705, []int{8}
+	return fileDescriptor_f9c348dec43a6705, []int{13}
 }
 
 func (m *UnavailableTokenEvent) XXX_Unmarshal(b []byte) error {
@@ -922,8 +1346,14 @@ func init() {
 	proto.RegisterEnum("rpc.Platform", Platform_name, Platform_value)
 	proto.RegisterType((*Message)(nil), "rpc.Message")
 	proto.RegisterType((*DeliveryFailure)(nil), "rpc.DeliveryFailure")
+	proto.RegisterType((*DeliveryReceipt)(nil), "rpc.DeliveryReceipt")
+	proto.RegisterType((*DeliveryDeferral)(nil), "rpc.DeliveryDeferral")
+	proto.RegisterType((*DeliveryWarning)(nil), "rpc.DeliveryWarning")
 	proto.RegisterType((*TokenRenewal)(nil), "rpc.TokenRenewal")
 	proto.RegisterType((*Event)(nil), "rpc.Event")
+	proto.RegisterType((*UpstreamRequest)(nil), "rpc.UpstreamRequest")
+	proto.RegisterType((*UpstreamMessage)(nil), "rpc.UpstreamMessage")
+	proto.RegisterMapType((map[string]string)(nil), "rpc.UpstreamMessage.DataEntry")
 	proto.RegisterType((*StatisticsQuery)(nil), "rpc.StatisticsQuery")
 	proto.RegisterType((*MasterStatistics)(nil), "rpc.MasterStatistics")
 	proto.RegisterMapType((map[string]*SlaveStatistics)(nil), "rpc.MasterStatistics.SlaveStatisticsEntry")
@@ -935,84 +1365,109 @@ func init() {
 func init() { proto.RegisterFile("master.proto", fileDescriptor_f9c348dec43a6705) }
 
 var fileDescriptor_f9c348dec43a6705 = []byte{
-	// 1221 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x56, 0x5d, 0x8e, 0x1a, 0x47,
-	0x10, 0xde, 0xe1, 0x67, 0x81, 0x02, 0xcc, 0xb8, 0xed, 0x8d, 0x10, 0xb2, 0x12, 0x84, 0xfc, 0x80,
-	0x57, 0x09, 0x58, 0x58, 0x51, 0xa2, 0x44, 0x8a, 0xc4, 0x7a, 0x59, 0xaf, 0xb3, 0xcb, 0x4f, 0x7a,
-	0x59, 0x6f, 0x9c, 0x17, 0xab, 0x99, 0x29, 0xb3, 0x93, 0x9d, 0xbf, 0x4c, 0x37, 0xd8, 0x93, 0xd7,
-	0xe4, 0x06, 0x79, 0xcb, 0x11, 0xf2, 0x92, 0x5b, 0xe4, 0x24, 0x39, 0x48, 0xd4, 0x3d, 0x3d, 0xcb,
-	0x80, 0xb8, 0x40, 0x5e, 0xa0, 0xeb, 0xab, 0xaf, 0xba, 0xaa, 0xab, 0xaa, 0xab, 0x07, 0x6a, 0x1e,
-	0xe3, 0x02, 0xa3, 0x5e, 0x18, 0x05, 0x22, 0x20, 0xf9, 0x28, 0xb4, 0x5a, 0x0d, 0x16, 0xfa, 0xbc,
-	0x2f, 0x7f, 0x12, 0xb4, 0x55, 0x5f, 0x5a, 0x5e, 0x7f, 0x69, 0x79, 0x5a, 0x3c, 0xfa, 0x80, 0x8b,
-	0x70, 0xc5, 0x6f, 0xfb, 0xfa, 0x3f, 0x65, 0x31, 0xdb, 0xeb, 0x33, 0x5b, 0xb3, 0x3a, 0xff, 0xe4,
-	0xa1, 0x34, 0x46, 0xce, 0xd9, 0x12, 0xc9, 0xe7, 0x00, 0x72, 0xbb, 0x73, 0x64, 0x36, 0x46, 0x4d,
-	0xa3, 0x6d, 0x74, 0xab, 0x83, 0x5a, 0x4f, 0x79, 0x48, 0x30, 0x9a, 0xd1, 0x93, 0x67, 0x50, 0x59,
-	0x5a, 0x9e, 0x26, 0xe7, 0x14, 0xb9, 0xda, 0x93, 0xee, 0x35, 0x77, 0xa3, 0x25, 0x5f, 0x42, 0x5d,
-	0x07, 0xa1, 0xe9, 0x15, 0x45, 0x6f, 0xf4, 0xd2, 0xd0, 0xb4, 0xc9, 0x36, 0x4b, 0x7a, 0x60, 0x76,
-	0xea, 0xa1, 0xaa, 0x3d, 0xc8, 0xd0, 0x53, 0x0f, 0xf7, 0x5a, 0xf2, 0x09, 0x1c, 0x8a, 0xe0, 0x0e,
-	0x7d, 0xde, 0xcc, 0xb7, 0xf3, 0xdd, 0x0a, 0xd5, 0x12, 0x79, 0x06, 0xe5, 0x30, 0x72, 0x82, 0xc8,
-	0x11, 0x71, 0xb3, 0xd0, 0x36, 0xba, 0x0f, 0x06, 0xf5, 0x5e, 0x14, 0x5a, 0xbd, 0x99, 0x06, 0xe9,
-	0xbd, 0x9a, 0x7c, 0x0a, 0x80, 0x1f, 0x43, 0x27, 0x62, 0xc2, 0x09, 0xfc, 0x66, 0xb1, 0x6d, 0x74,
-	0xeb, 0x34, 0x83, 0x90, 0x36, 0x54, 0xad, 0xc0, 0x75, 0x59, 0xc8, 0xf1, 0x02, 0xe3, 0x66, 0xad,
-	0x6d, 0x74, 0x2b, 0x34, 0x0b, 0x91, 0x26, 0x94, 0x42, 0x16, 0xbb, 0x01, 0xb3, 0x9b, 0x87, 0x4a,
-	0x9b, 0x8a, 0xa4, 0x0f, 0x10, 0xb2, 0x88, 0x79, 0x28, 0x30, 0xe2, 0xcd, 0x92, 0x3e, 0xbd, 0x4c,
-	0xd6, 0xec, 0x1e, 0xa6, 0x19, 0x0a, 0x21, 0x50, 0x58, 0x04, 0x76, 0xdc, 0x04, 0xb5, 0x8f, 0x5a,
-	0x93, 0x27, 0x50, 0x59, 0x30, 0xdf, 0xbe, 0x71, 0x6c, 0x71, 0xdb, 0x2c, 0xb7, 0x8d, 0x6e, 0x91,
-	0x6e, 0x80, 0xce, 0x6f, 0x06, 0x34, 0x4e, 0xd1, 0x75, 0xd6, 0x18, 0xc5, 0x67, 0xcc, 0x71, 0x57,
-	0x11, 0x92, 0xa7, 0x50, 0xb8, 0x73, 0x7c, 0x5b, 0x95, 0xf2, 0xc1, 0xc0, 0x54, 0x27, 0xd7, 0xba,
-	0x0b, 0xc7, 0xb7, 0xa9, 0xd2, 0x92, 0xc7, 0x50, 0x54, 0xd9, 0x52, 0x45, 0xac, 0xd0, 0x44, 0x90,
-	0x19, 0xe5, 0x82, 0x89, 0x95, 0xcc, 0xa8, 0x84, 0xb5, 0x24, 0xa3, 0x10, 0x8e, 0x87, 0x5c, 0x30,
-	0x2f, 0x54, 0x29, 0xad, 0xd3, 0x0d, 0xd0, 0xa1, 0x50, 0x9b, 0x4b, 0x73, 0x8a, 0x3e, 0x7e, 0x60,
-	0xae, 0x4c, 0x5a, 0x84, 0x16, 0xfa, 0x42, 0xa1, 0x2a, 0x90, 0x0a, 0xcd, 0x42, 0x92, 0xe1, 0x32,
-	0x81, 0x5c, 0x33, 0x92, 0x18, 0xb2, 0x50, 0xe7, 0x4f, 0x03, 0x8a, 0xa3, 0x35, 0xfa, 0x42, 0x55,
-	0xd3, 0x65, 0xe2, 0x7d, 0x10, 0x79, 0xfa, 0x4c, 0xba, 0x9a, 0x1a, 0xa4, 0xf7, 0x6a, 0xd2, 0x83,
-	0xc3, 0xf7, 0xcc, 0x71, 0xd1, 0xd6, 0xad, 0xf9, 0x58, 0x11, 0x77, 0x12, 0x74, 0x7e, 0x40, 0x35,
-	0x8b, 0x7c, 0x01, 0xa5, 0x48, 0xc6, 0x8c, 0xb6, 0x3a, 0x6f, 0x75, 0xf0, 0x50, 0x19, 0x64, 0x0f,
-	0x73, 0x7e, 0x40, 0x53, 0xce, 0x49, 0x09, 0x8a, 0x28, 0x43, 0xea, 0x3c, 0x84, 0xc6, 0x95, 0x60,
-	0xc2, 0xe1, 0xc2, 0xb1, 0xf8, 0x0f, 0x2b, 0x8c, 0xe2, 0xce, 0x1f, 0x79, 0x30, 0xc7, 0xea, 0xba,
-	0x6e, 0x34, 0xb2, 0x37, 0xd6, 0x18, 0x71, 0xd9, 0x5a, 0x49, 0x12, 0x52, 0x91, 0xb4, 0xa0, 0x6c,
-	0x05, 0x5e, 0xe8, 0xb8, 0xfa, 0x1a, 0x55, 0xe8, 0xbd, 0x4c, 0x3a, 0x50, 0xf3, 0x57, 0xde, 0x2c,
-	0x0a, 0x2c, 0xe4, 0x3c, 0x88, 0x54, 0x68, 0x45, 0xba, 0x85, 0xc9, 0x9d, 0xfd, 0x95, 0x37, 0x67,
-	0xfc, 0x4e, 0x95, 0xa3, 0x48, 0x53, 0x91, 0x7c, 0x0b, 0x75, 0x0f, 0xbd, 0x4d, 0x10, 0xaa, 0xa9,
-	0xab, 0x83, 0x23, 0x75, 0xb2, 0x31, 0x7a, 0x41, 0x14, 0x6f, 0x94, 0x74, 0x9b, 0x2b, 0xaf, 0x83,
-	0x74, 0x83, 0xbe, 0xed, 0xf8, 0x4b, 0xd5, 0xcf, 0x45, 0x9a, 0x41, 0xc8, 0x1c, 0x1a, 0xdc, 0x65,
-	0x6b, 0xcc, 0x6c, 0x5f, 0x6a, 0xe7, 0xbb, 0xd5, 0xc1, 0x71, 0xb2, 0xfd, 0x4e, 0x02, 0x7a, 0x57,
-	0xdb, 0xe4, 0x91, 0x2f, 0xa2, 0x98, 0xee, 0x6e, 0xd1, 0xfa, 0x11, 0x1e, 0xef, 0x23, 0x12, 0x13,
-	0xf2, 0x77, 0x18, 0xeb, 0xd4, 0xc9, 0x25, 0x39, 0x86, 0xe2, 0x9a, 0xb9, 0x2b, 0xdc, 0xaa, 0xef,
-	0x8e, 0x2d, 0x4d, 0x28, 0xdf, 0xe4, 0xbe, 0x36, 0x3a, 0xbf, 0x17, 0xa0, 0xb1, 0xa3, 0xfe, 0xff,
-	0x15, 0xe5, 0x09, 0x54, 0x3c, 0xf6, 0x71, 0xb8, 0x44, 0x5f, 0x70, 0x5d, 0x93, 0x0d, 0x40, 0xba,
-	0xd0, 0x90, 0x5e, 0x02, 0xc1, 0x5c, 0x8a, 0xbf, 0xac, 0x90, 0x0b, 0x35, 0x6a, 0x8a, 0x74, 0x17,
-	0x26, 0x4f, 0xa1, 0xee, 0xaf, 0x3c, 0x7d, 0x1b, 0x64, 0x7d, 0x93, 0x71, 0xb2, 0x0d, 0x92, 0xe7,
-	0xf0, 0x68, 0x03, 0xa0, 0x7d, 0x8a, 0x6b, 0xc7, 0x42, 0xae, 0x86, 0x77, 0x91, 0xee, 0x53, 0x91,
-	0x1e, 0x10, 0x21, 0xfd, 0x8c, 0x3e, 0xa2, 0xb5, 0x92, 0x53, 0x73, 0xee, 0x78, 0xa8, 0x86, 0x58,
-	0x9e, 0xee, 0xd1, 0x48, 0x0f, 0xc9, 0x4d, 0xdf, 0x36, 0xa8, 0x2a, 0x83, 0x7d, 0x2a, 0xd9, 0x96,
-	0x2e, 0xe3, 0xe2, 0x3a, 0xb4, 0x99, 0x40, 0x35, 0x84, 0xf3, 0x34, 0x83, 0x48, 0x7d, 0x84, 0x22,
-	0x8a, 0x5f, 0x06, 0x2b, 0x5f, 0x34, 0xeb, 0x49, 0xdb, 0x6e, 0x90, 0xce, 0xbf, 0x06, 0x98, 0xbb,
-	0x59, 0x96, 0x13, 0x90, 0xb9, 0x6e, 0x60, 0xa9, 0x2e, 0x28, 0xd0, 0x44, 0x90, 0x5b, 0xa9, 0x90,
-	0x87, 0x4a, 0x95, 0x53, 0xaa, 0x0c, 0x22, 0x7b, 0x92, 0xc7, 0xc9, 0x78, 0x2c, 0x50, 0xb9, 0x94,
-	0x55, 0xf7, 0x94, 0x2d, 0x57, 0x55, 0x2f, 0xd0, 0x54, 0x94, 0x1e, 0xde, 0x47, 0x88, 0x49, 0xb5,
-	0x0b, 0x34, 0x11, 0x64, 0x39, 0x6f, 0x91, 0x85, 0x89, 0x83, 0x43, 0xa5, 0xd9, 0x00, 0x72, 0x37,
-	0x29, 0x5c, 0xc5, 0xc9, 0x8b, 0x51, 0xa0, 0xa9, 0x28, 0x67, 0xa6, 0x5c, 0x4e, 0x17, 0x3f, 0xa3,
-	0x25, 0xb8, 0x2a, 0x5e, 0x81, 0x66, 0xa1, 0xce, 0x05, 0x1c, 0x5d, 0xfb, 0x6c, 0xcd, 0x1c, 0x97,
-	0x2d, 0x5c, 0x54, 0x53, 0x2c, 0x19, 0xa1, 0x5b, 0xe3, 0xdb, 0xd8, 0x19, 0xdf, 0xfb, 0x9f, 0x82,
-	0xe3, 0xaf, 0xa0, 0x9c, 0xbe, 0x97, 0xa4, 0x0c, 0x85, 0xf3, 0xd7, 0xaf, 0xce, 0xcd, 0x03, 0x02,
-	0x70, 0x38, 0x99, 0xd2, 0xf1, 0xf0, 0xd2, 0x34, 0x48, 0x09, 0xf2, 0x97, 0xd3, 0x1b, 0x33, 0x47,
-	0x6a, 0x50, 0x7e, 0x33, 0xa2, 0x6f, 0xdf, 0x49, 0x29, 0x7f, 0xfc, 0x3d, 0x54, 0x33, 0xcf, 0x0d,
-	0x79, 0x04, 0x8d, 0xf9, 0x68, 0x3c, 0x9b, 0xd2, 0x21, 0x7d, 0xfb, 0x6e, 0x44, 0xe9, 0x94, 0x9a,
-	0x07, 0xe4, 0x21, 0xd4, 0x5f, 0x4f, 0xde, 0x0c, 0x2f, 0x5f, 0x9f, 0xbe, 0x9b, 0x4f, 0x2f, 0x46,
-	0x13, 0xd3, 0x90, 0xbc, 0x14, 0x9a, 0x0d, 0xdf, 0x5e, 0x4e, 0x87, 0xa7, 0x66, 0xee, 0xf8, 0x0d,
-	0x94, 0xd3, 0x31, 0x4f, 0xaa, 0x50, 0xba, 0x9e, 0x5c, 0x4c, 0xa6, 0x37, 0x13, 0xf3, 0x40, 0x46,
-	0x34, 0x9c, 0x4d, 0xae, 0x92, 0x28, 0x5e, 0xbd, 0x1c, 0x9b, 0x39, 0xb9, 0x38, 0x4b, 0x17, 0xc3,
-	0xcb, 0xb9, 0x99, 0x97, 0x16, 0x37, 0xa3, 0x93, 0xd9, 0xf5, 0xd5, 0xb9, 0x59, 0x50, 0xe8, 0xe9,
-	0xd8, 0x2c, 0xb6, 0x72, 0xa6, 0x31, 0xf8, 0xdb, 0x80, 0xda, 0x49, 0xe0, 0x8a, 0x5f, 0x5f, 0x31,
-	0x81, 0x1f, 0x58, 0x4c, 0x3a, 0x50, 0xb8, 0x42, 0xdf, 0x26, 0x35, 0x7d, 0x23, 0xd5, 0xb7, 0x51,
-	0x0b, 0x94, 0xa4, 0x72, 0xf8, 0xdc, 0x20, 0xdf, 0x41, 0xe3, 0x0c, 0x85, 0x75, 0x9b, 0xed, 0xa1,
-	0x64, 0x00, 0x6d, 0xbf, 0x05, 0xad, 0xa3, 0xbd, 0xc3, 0x50, 0x0e, 0x01, 0x65, 0x7f, 0x86, 0x68,
-	0x2f, 0x98, 0x75, 0x47, 0xb6, 0x3e, 0xb3, 0x5a, 0x2d, 0x65, 0xb5, 0xb7, 0x80, 0xcf, 0x8d, 0x93,
-	0x17, 0x3f, 0x7d, 0xb6, 0x74, 0xc4, 0xed, 0x6a, 0xd1, 0xb3, 0x02, 0xaf, 0xaf, 0x62, 0x1f, 0xf9,
-	0x4b, 0xc7, 0xc7, 0x3e, 0x0b, 0x1d, 0xde, 0x8f, 0x42, 0xeb, 0xaf, 0x5c, 0x23, 0x03, 0xf7, 0x68,
-	0x68, 0x2d, 0x0e, 0xd5, 0xe7, 0xde, 0x8b, 0xff, 0x02, 0x00, 0x00, 0xff, 0xff, 0xd7, 0xfd, 0xab,
-	0x54, 0x49, 0x0a, 0x00, 0x00,
+	// 1618 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x57, 0x5f, 0x6f, 0x23, 0x49,
+	0x11, 0xcf, 0xd8, 0xe3, 0x7f, 0xe5, 0x78, 0x3d, 0xdb, 0x97, 0xa0, 0x91, 0x75, 0x5a, 0x2c, 0xeb,
+	0x40, 0xbe, 0x08, 0x9c, 0x28, 0x0b, 0x3a, 0x04, 0x08, 0xc9, 0x59, 0x7b, 0xd7, 0x21, 0xff, 0x4c,
+	0xc7, 0xb9, 0xb0, 0xbc, 0x2c, 0x9d, 0x99, 0x8a, 0x33, 0x64, 0xfe, 0x5d, 0x4f, 0xdb, 0xbb, 0xe6,
+	0x81, 0x27, 0x24, 0x3e, 0x00, 0xdf, 0x82, 0x27, 0xde, 0xe0, 0x8d, 0x2f, 0xc0, 0xc7, 0xe0, 0x83,
+	0xa0, 0xee, 0xe9, 0xf1, 0x8c, 0x8d, 0x8f, 0xf7, 0x7b, 0xb1, 0xbb, 0x7e, 0x55, 0xdd, 0x55, 0x5d,
+	0x55, 0x5d, 0x55, 0x03, 0xfb, 0x01, 0x4b, 0x04, 0xf2, 0x41, 0xcc, 0x23, 0x11, 0x91, 0x32, 0x8f,
+	0x9d, 0x4e, 0x9b, 0xc5, 0x61, 0x72, 0x2c, 0x7f, 0x52, 0xb4, 0xd3, 0x9a, 0x3b, 0xc1, 0xf1, 0xdc,
+	0x09, 0x34, 0x79, 0xf8, 0x11, 0x1f, 0xe2, 0x45, 0xf2, 0x74, 0xac, 0xff, 0x33, 0x29, 0xe6, 0x06,
+	0xc7, 0xcc, 0xd5, 0x52, 0xbd, 0x7f, 0x9a, 0x50, 0xbb, 0xc2, 0x24, 0x61, 0x73, 0x24, 0x3f, 0x02,
+	0x90, 0xc7, 0x4d, 0x90, 0xb9, 0xc8, 0x6d, 0xa3, 0x6b, 0xf4, 0x9b, 0xa7, 0xfb, 0x03, 0xa5, 0x21,
+	0xc5, 0x68, 0x81, 0x4f, 0xbe, 0x84, 0xc6, 0xdc, 0x09, 0xb4, 0x70, 0x49, 0x09, 0x37, 0x07, 0x52,
+	0xbd, 0x96, 0xcd, 0xb9, 0xe4, 0xa7, 0xd0, 0xd2, 0x46, 0x68, 0xf1, 0x86, 0x12, 0x6f, 0x0f, 0x32,
+	0xd3, 0xf4, 0x96, 0x4d, 0x29, 0xa9, 0x81, 0xb9, 0x99, 0x86, 0xa6, 0xd6, 0x20, 0x4d, 0xcf, 0x34,
+	0xac, 0xb9, 0xd2, 0xf4, 0x98, 0x25, 0x99, 0xe9, 0xed, 0x5d, 0xa6, 0xe7, 0x7c, 0xf2, 0x3d, 0xa8,
+	0x8a, 0xe8, 0x19, 0xc3, 0xc4, 0x2e, 0x77, 0xcb, 0xfd, 0x06, 0xd5, 0x14, 0xf9, 0x12, 0xea, 0x31,
+	0xf7, 0x22, 0xee, 0x89, 0x95, 0x6d, 0x76, 0x8d, 0xfe, 0x8b, 0xd3, 0xd6, 0x80, 0xc7, 0xce, 0x60,
+	0xaa, 0x41, 0xba, 0x66, 0x93, 0x57, 0x00, 0xf8, 0x29, 0xf6, 0x38, 0x13, 0x5e, 0x14, 0xda, 0x95,
+	0xae, 0xd1, 0x6f, 0xd1, 0x02, 0x42, 0xba, 0xd0, 0x74, 0x22, 0xdf, 0x67, 0x71, 0x82, 0x17, 0xb8,
+	0xb2, 0xf7, 0xbb, 0x46, 0xbf, 0x41, 0x8b, 0x10, 0xe9, 0x40, 0xdd, 0x79, 0x62, 0x61, 0x88, 0x7e,
+	0x62, 0xb7, 0x94, 0x19, 0x6b, 0x9a, 0xd8, 0x50, 0x8b, 0xd9, 0xca, 0x8f, 0x98, 0x6b, 0x57, 0xd5,
+	0xce, 0x8c, 0x24, 0xc7, 0xf2, 0xa2, 0x9c, 0x05, 0x28, 0x90, 0x27, 0x76, 0x4d, 0xfb, 0x51, 0xba,
+	0x7d, 0xba, 0x86, 0x69, 0x41, 0x84, 0x10, 0x30, 0x1f, 0x22, 0x77, 0x65, 0x83, 0x3a, 0x47, 0xad,
+	0xc9, 0xe7, 0xd0, 0x78, 0x60, 0xa1, 0x7b, 0xef, 0xb9, 0xe2, 0xc9, 0xae, 0x77, 0x8d, 0x7e, 0x85,
+	0xe6, 0x00, 0xf9, 0x21, 0xbc, 0xe0, 0x18, 0x47, 0x5c, 0x50, 0x74, 0xd0, 0x8b, 0x45, 0x62, 0xbf,
+	0xe8, 0x1a, 0xfd, 0x3a, 0xdd, 0x42, 0x7b, 0xff, 0x32, 0xa0, 0x3d, 0x42, 0xdf, 0x5b, 0x22, 0x5f,
+	0xbd, 0x65, 0x9e, 0xbf, 0xe0, 0x48, 0xbe, 0x00, 0xf3, 0xd9, 0x0b, 0x5d, 0x95, 0x3c, 0x2f, 0x4e,
+	0x2d, 0xe5, 0x3d, 0xcd, 0xbb, 0xf0, 0x42, 0x97, 0x2a, 0x2e, 0x39, 0x80, 0x8a, 0xf2, 0xb8, 0x4a,
+	0x9b, 0x06, 0x4d, 0x09, 0x19, 0x95, 0x44, 0x30, 0xb1, 0x90, 0x51, 0x91, 0xb0, 0xa6, 0xa4, 0xb5,
+	0xc2, 0x0b, 0x30, 0x11, 0x2c, 0x88, 0x55, 0x58, 0x5a, 0x34, 0x07, 0xe4, 0x2e, 0x19, 0xe6, 0xf3,
+	0x91, 0x0a, 0x42, 0x83, 0x6a, 0x8a, 0xf4, 0x60, 0x5f, 0xae, 0xee, 0x42, 0xef, 0x9b, 0x05, 0x9e,
+	0x8f, 0xb4, 0x1f, 0x37, 0xb0, 0x9e, 0x93, 0x5f, 0x40, 0xdf, 0x2a, 0x37, 0xcd, 0xd8, 0x32, 0x4d,
+	0x2b, 0x29, 0xfd, 0x5f, 0x25, 0xe5, 0x1d, 0x4a, 0xfe, 0x04, 0x56, 0xa6, 0x64, 0x84, 0x8f, 0xc8,
+	0x39, 0xf3, 0xbf, 0x45, 0xcb, 0x2b, 0x00, 0x8e, 0x82, 0xaf, 0x86, 0x8f, 0x42, 0x3f, 0xa9, 0x16,
+	0x2d, 0x20, 0xd2, 0x11, 0x4e, 0xc4, 0x7c, 0x4c, 0x1c, 0x74, 0x95, 0xaa, 0x3a, 0xcd, 0x81, 0x82,
+	0xfb, 0xcc, 0xa2, 0xfb, 0x7a, 0x7f, 0x29, 0x84, 0xe9, 0x9e, 0xf1, 0xd0, 0x0b, 0xe7, 0xdf, 0x7e,
+	0x4b, 0x7d, 0x42, 0x69, 0x23, 0x00, 0x1d, 0xa8, 0xcb, 0x57, 0x39, 0x5b, 0xc5, 0xa8, 0x6f, 0xb8,
+	0xa6, 0xc9, 0x11, 0x58, 0x5e, 0x28, 0x6f, 0x85, 0xee, 0x34, 0x93, 0x49, 0xf5, 0xff, 0x0f, 0xde,
+	0xa3, 0xb0, 0x3f, 0x93, 0x8a, 0x28, 0x86, 0xf8, 0x91, 0xf9, 0xf2, 0x8d, 0x70, 0x74, 0x30, 0x14,
+	0xb3, 0x82, 0x2d, 0x45, 0x48, 0x4a, 0xf8, 0x4c, 0x60, 0xa2, 0x25, 0x52, 0xb3, 0x8a, 0x50, 0xef,
+	0x1f, 0x25, 0xa8, 0x8c, 0x97, 0x18, 0x0a, 0xf5, 0x78, 0x7d, 0x26, 0x1e, 0x23, 0x1e, 0xd8, 0x46,
+	0xf1, 0xf1, 0x6a, 0x90, 0xae, 0xd9, 0x64, 0x00, 0xd5, 0x47, 0xe6, 0xf9, 0xe8, 0xea, 0xba, 0x75,
+	0xa0, 0x04, 0xb7, 0x72, 0x79, 0xb2, 0x47, 0xb5, 0x14, 0xf9, 0x31, 0xd4, 0xb8, 0xb4, 0x59, 0xbb,
+	0xbd, 0x79, 0xfa, 0x52, 0x6d, 0x28, 0x5e, 0x66, 0xb2, 0x47, 0x33, 0x19, 0xf2, 0x13, 0x68, 0xb8,
+	0xe9, 0x59, 0xe8, 0xda, 0xe6, 0x0e, 0x0d, 0x3a, 0xd9, 0x26, 0x7b, 0x34, 0x17, 0x24, 0xaf, 0xa1,
+	0xee, 0x62, 0xea, 0x31, 0x95, 0xca, 0xcd, 0xd3, 0xc3, 0x8d, 0x4d, 0x59, 0xf2, 0x4c, 0xf6, 0xe8,
+	0x5a, 0x90, 0x9c, 0x40, 0xed, 0x63, 0x1a, 0x53, 0xbb, 0xba, 0x43, 0x91, 0x8e, 0xb7, 0x34, 0x4e,
+	0x8b, 0x9d, 0xd5, 0xa0, 0x82, 0xd2, 0x5f, 0xbd, 0x7b, 0x68, 0xdf, 0xc5, 0x89, 0xe0, 0xc8, 0x02,
+	0x8a, 0xdf, 0x2c, 0x30, 0x11, 0xe4, 0x07, 0x50, 0x7d, 0x2a, 0x16, 0xff, 0x62, 0x3d, 0x97, 0xee,
+	0x48, 0x99, 0x84, 0x40, 0x99, 0x39, 0xcf, 0x69, 0x34, 0x26, 0x7b, 0x54, 0x12, 0x67, 0x0d, 0xe9,
+	0x22, 0x75, 0x4a, 0xef, 0xdf, 0x46, 0x7e, 0x72, 0xd6, 0x5a, 0x3e, 0x87, 0x46, 0x90, 0x2e, 0xcf,
+	0x5d, 0x1d, 0xe8, 0x1c, 0x90, 0x35, 0xea, 0x91, 0x47, 0x81, 0x8e, 0xaf, 0x5a, 0xab, 0xf2, 0xc8,
+	0x04, 0xce, 0x23, 0xbe, 0xca, 0x92, 0x2e, 0xa3, 0xc9, 0x29, 0x98, 0x2e, 0x13, 0xcc, 0x36, 0xbb,
+	0xe5, 0x7e, 0xf3, 0xf4, 0x95, 0xba, 0xf2, 0x96, 0xc6, 0xc1, 0x88, 0x09, 0x36, 0x0e, 0x05, 0x5f,
+	0x51, 0x25, 0xdb, 0xf9, 0x0a, 0x1a, 0x6b, 0x88, 0x58, 0x50, 0x7e, 0xc6, 0x95, 0x36, 0x44, 0x2e,
+	0xe5, 0x8b, 0x58, 0x32, 0x7f, 0x81, 0x59, 0x49, 0x52, 0xc4, 0xcf, 0x4b, 0x3f, 0x33, 0x7a, 0x2f,
+	0xa1, 0x7d, 0x2b, 0x98, 0xf0, 0x12, 0xe1, 0x39, 0xc9, 0x6f, 0x16, 0xc8, 0x57, 0xbd, 0xbf, 0x96,
+	0xc1, 0xba, 0x52, 0x0d, 0x39, 0xe7, 0xc8, 0x9a, 0xbd, 0x44, 0x9e, 0xc8, 0x76, 0x90, 0x9e, 0x9b,
+	0x91, 0xea, 0x2a, 0x51, 0x10, 0x7b, 0xbe, 0x7e, 0xd5, 0x0d, 0xba, 0xa6, 0x65, 0x05, 0x09, 0x17,
+	0xc1, 0x94, 0x47, 0x0e, 0x26, 0x49, 0xc4, 0xd5, 0x55, 0x2b, 0x74, 0x03, 0x93, 0x27, 0x87, 0x8b,
+	0x60, 0xc6, 0x92, 0x67, 0x95, 0x4d, 0x15, 0x9a, 0x91, 0xe4, 0x17, 0xd0, 0x0a, 0x30, 0xc8, 0x8d,
+	0xd8, 0x48, 0x9c, 0x2b, 0x0c, 0x22, 0xbe, 0xca, 0x99, 0x74, 0x53, 0x56, 0x96, 0x1b, 0xa9, 0x06,
+	0x43, 0x37, 0x4b, 0x9f, 0x0a, 0x2d, 0x20, 0x64, 0x06, 0xed, 0xc4, 0x67, 0x4b, 0x2c, 0x1c, 0x5f,
+	0x53, 0x0e, 0x3f, 0x4a, 0x8f, 0xdf, 0x72, 0xc0, 0xe0, 0x76, 0x53, 0x38, 0x75, 0xfe, 0xf6, 0x11,
+	0x9d, 0xdf, 0xc2, 0xc1, 0x2e, 0xc1, 0x1d, 0x21, 0x39, 0x2a, 0x86, 0x24, 0xcb, 0xec, 0xad, 0xbd,
+	0xc5, 0x40, 0xfd, 0xd9, 0x84, 0xf6, 0x16, 0xfb, 0xbb, 0x17, 0x14, 0xf9, 0x50, 0xd8, 0xa7, 0xe1,
+	0x1c, 0x43, 0x91, 0xe8, 0x98, 0xe4, 0x00, 0xe9, 0x43, 0x5b, 0x6a, 0x89, 0x04, 0xf3, 0xf5, 0x9b,
+	0x55, 0x23, 0x40, 0x85, 0x6e, 0xc3, 0xe4, 0x0b, 0x68, 0x85, 0x8b, 0x40, 0xd7, 0x01, 0x19, 0xdf,
+	0xb4, 0xcd, 0x6f, 0x82, 0xe4, 0x04, 0x3e, 0xcb, 0x01, 0x74, 0x47, 0xb8, 0xf4, 0x1c, 0x4c, 0xd4,
+	0x78, 0x56, 0xa1, 0xbb, 0x58, 0x64, 0x00, 0x44, 0x48, 0x3d, 0xe3, 0x4f, 0xe8, 0x2c, 0xe4, 0xa4,
+	0x33, 0xf3, 0x02, 0x54, 0xc3, 0x45, 0x99, 0xee, 0xe0, 0x48, 0x0d, 0x69, 0xb9, 0xde, 0xdc, 0xd0,
+	0x54, 0x1b, 0x76, 0xb1, 0x64, 0x5a, 0xfa, 0x2c, 0x11, 0x77, 0xb1, 0xcb, 0x04, 0xaa, 0xc1, 0xa9,
+	0x4c, 0x0b, 0xc8, 0xba, 0x4b, 0xbe, 0x89, 0x16, 0xa1, 0xb0, 0x5b, 0x69, 0xda, 0xe6, 0x48, 0xef,
+	0x3f, 0x06, 0x58, 0xdb, 0x5e, 0x96, 0xcf, 0x9b, 0xf9, 0x7e, 0xe4, 0xa8, 0x2c, 0x30, 0x69, 0x4a,
+	0xc8, 0xa3, 0x94, 0xc9, 0x43, 0xc5, 0x2a, 0x29, 0x56, 0x01, 0x91, 0x39, 0x99, 0xac, 0xd2, 0x71,
+	0xc4, 0xa4, 0x72, 0x29, 0xa3, 0x1e, 0xa8, 0xbd, 0x69, 0x97, 0x35, 0x69, 0x46, 0x4a, 0x0d, 0x8f,
+	0x1c, 0x31, 0x8d, 0xb6, 0x49, 0x53, 0x42, 0x86, 0xf3, 0x09, 0x59, 0x9c, 0x2a, 0xa8, 0x2a, 0x4e,
+	0x0e, 0xc8, 0xd3, 0x24, 0x71, 0xbb, 0x4a, 0x27, 0x39, 0x93, 0x66, 0xa4, 0x6c, 0x7c, 0x72, 0x79,
+	0xf3, 0xf0, 0x07, 0x74, 0x44, 0xa2, 0x82, 0x67, 0xd2, 0x22, 0xd4, 0xbb, 0x80, 0xc3, 0xbb, 0x90,
+	0x2d, 0x99, 0xe7, 0xb3, 0x07, 0x1f, 0x55, 0x2b, 0x4a, 0xfb, 0xe0, 0xc6, 0xb8, 0x64, 0x6c, 0x8f,
+	0x4b, 0x3b, 0x47, 0xaf, 0xa3, 0xaf, 0xa0, 0x9e, 0xcd, 0xb8, 0xa4, 0x0e, 0xe6, 0xe4, 0xfc, 0xdd,
+	0xc4, 0xda, 0x23, 0x00, 0xd5, 0xeb, 0x1b, 0x7a, 0x35, 0xbc, 0xb4, 0x0c, 0x52, 0x83, 0xf2, 0xe5,
+	0xcd, 0xbd, 0x55, 0x22, 0xfb, 0x50, 0xff, 0x7a, 0x4c, 0xdf, 0x7f, 0x90, 0x54, 0xf9, 0xe8, 0xd7,
+	0xd0, 0x2c, 0x8c, 0x77, 0xe4, 0x33, 0x68, 0xcf, 0xc6, 0x57, 0xd3, 0x1b, 0x3a, 0xa4, 0xef, 0x3f,
+	0x8c, 0x29, 0xbd, 0xa1, 0xd6, 0x1e, 0x79, 0x09, 0xad, 0xf3, 0xeb, 0xaf, 0x87, 0x97, 0xe7, 0xa3,
+	0x0f, 0xb3, 0x9b, 0x8b, 0xf1, 0xb5, 0x65, 0x48, 0xb9, 0x0c, 0x9a, 0x0e, 0xdf, 0x5f, 0xde, 0x0c,
+	0x47, 0x56, 0xe9, 0xe8, 0xf7, 0x50, 0xcf, 0x7a, 0x35, 0x69, 0x42, 0xed, 0xee, 0xfa, 0xe2, 0xfa,
+	0xe6, 0xfe, 0xda, 0xda, 0x93, 0x16, 0x0d, 0xa7, 0xd7, 0xb7, 0xa9, 0x15, 0xef, 0xde, 0x5c, 0x59,
+	0x25, 0xb9, 0x78, 0x9b, 0x2d, 0x86, 0x97, 0x33, 0xab, 0x2c, 0x77, 0xdc, 0x8f, 0xcf, 0xa6, 0x77,
+	0xb7, 0x13, 0xcb, 0x54, 0xe8, 0xe8, 0xca, 0xaa, 0xc8, 0xad, 0xd3, 0xe1, 0xed, 0xad, 0x55, 0xed,
+	0x94, 0x2c, 0xe3, 0xf4, 0xef, 0x25, 0xd8, 0x3f, 0x8b, 0x7c, 0xf1, 0xc7, 0x77, 0x4c, 0xe0, 0x47,
+	0xb6, 0x22, 0x3d, 0x30, 0x6f, 0x31, 0x74, 0xc9, 0xbe, 0x7e, 0x9b, 0xaa, 0x75, 0x74, 0x40, 0x51,
+	0xca, 0x9b, 0x27, 0x06, 0xf9, 0x15, 0xb4, 0xdf, 0xa2, 0x70, 0x9e, 0x8a, 0xd9, 0x94, 0x96, 0xa2,
+	0xcd, 0xae, 0xd0, 0x39, 0xdc, 0x59, 0x16, 0x65, 0x39, 0x50, 0xfb, 0xdf, 0x22, 0xba, 0x0f, 0xcc,
+	0x79, 0x26, 0x1b, 0xdf, 0x25, 0x9d, 0x4e, 0xda, 0xbd, 0x76, 0x85, 0xf2, 0xc4, 0x20, 0xbf, 0x84,
+	0xd6, 0x15, 0x0b, 0xd9, 0x1c, 0xdf, 0xa4, 0x9f, 0x06, 0xe4, 0x20, 0xdd, 0xac, 0x49, 0xfd, 0xda,
+	0x3b, 0x87, 0x5b, 0x68, 0x12, 0x47, 0x61, 0x82, 0x64, 0x08, 0x6d, 0x35, 0x6a, 0x2c, 0x31, 0xeb,
+	0x8e, 0xda, 0xf4, 0xad, 0xc6, 0xdf, 0x39, 0xd8, 0xd5, 0x42, 0xfb, 0xc6, 0x89, 0x71, 0xf6, 0xfa,
+	0x77, 0xdf, 0x9f, 0x7b, 0xe2, 0x69, 0xf1, 0x30, 0x70, 0xa2, 0xe0, 0x58, 0x39, 0x6f, 0x1c, 0xce,
+	0xbd, 0x10, 0x8f, 0x59, 0xec, 0x25, 0xc7, 0x3c, 0x76, 0xfe, 0x56, 0x6a, 0x17, 0xe0, 0x01, 0x8d,
+	0x9d, 0x87, 0xaa, 0xfa, 0xb6, 0x7c, 0xfd, 0xdf, 0x01, 0x00, 0xd2, 0xe6, 0xfc, 0xdd, 0xb6, 0x0e,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1034,6 +1489,12 @@ type BoltzGatewayClient interface {
 	FetchStatistics(ctx context.Context, in *StatisticsQuery, opts ...grpc.CallOption) (*MasterStatistics, error)
 	// APNsのフィードバックサービスから無効トークンを取得する。
 	FetchFeedback(ctx context.Context, in *apns.Header, opts ...grpc.CallOption) (BoltzGateway_FetchFeedbackClient, error)
+	// ManageChannel はLive Activityのブロードキャストチャネルを作成・参照・削除する。
+	ManageChannel(ctx context.Context, in *apns.ChannelRequest, opts ...grpc.CallOption) (*apns.ChannelResponse, error)
+	// ReceiveUpstream はFCM XMPP API(CCS)で端末から送られた上りメッセージを受け取る。
+	// 最初のUpstreamRequestでgcm.Headerを送り、以降は処理を終えたメッセージごとにackを送る。
+	// ackを送らなかったメッセージはCCSが再送するので、messageIdで重複を取り除くこと。
+	ReceiveUpstream(ctx context.Context, opts ...grpc.CallOption) (BoltzGateway_ReceiveUpstreamClient, error)
 }
 
 type boltzGatewayClient struct {
@@ -1117,6 +1578,46 @@ func (x *boltzGatewayFetchFeedbackClient) Recv() (*UnavailableTokenEvent, error)
 	return m, nil
 }
 
+func (c *boltzGatewayClient) ManageChannel(ctx context.Context, in *apns.ChannelRequest, opts ...grpc.CallOption) (*apns.ChannelResponse, error) {
+	out := new(apns.ChannelResponse)
+	err := c.cc.Invoke(ctx, "/rpc.BoltzGateway/ManageChannel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *boltzGatewayClient) ReceiveUpstream(ctx context.Context, opts ...grpc.CallOption) (BoltzGateway_ReceiveUpstreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_BoltzGateway_serviceDesc.Streams[2], "/rpc.BoltzGateway/ReceiveUpstream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &boltzGatewayReceiveUpstreamClient{stream}
+	return x, nil
+}
+
+type BoltzGateway_ReceiveUpstreamClient interface {
+	Send(*UpstreamRequest) error
+	Recv() (*UpstreamMessage, error)
+	grpc.ClientStream
+}
+
+type boltzGatewayReceiveUpstreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *boltzGatewayReceiveUpstreamClient) Send(m *UpstreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *boltzGatewayReceiveUpstreamClient) Recv() (*UpstreamMessage, error) {
+	m := new(UpstreamMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // BoltzGatewayServer is the server API for BoltzGateway service.
 type BoltzGatewayServer interface {
 	// Send はMessageを各デバイスへ送信する。
@@ -1126,6 +1627,12 @@ type BoltzGatewayServer interface {
 	FetchStatistics(context.Context, *StatisticsQuery) (*MasterStatistics, error)
 	// APNsのフィードバックサービスから無効トークンを取得する。
 	FetchFeedback(*apns.Header, BoltzGateway_FetchFeedbackServer) error
+	// ManageChannel はLive Activityのブロードキャストチャネルを作成・参照・削除する。
+	ManageChannel(context.Context, *apns.ChannelRequest) (*apns.ChannelResponse, error)
+	// ReceiveUpstream はFCM XMPP API(CCS)で端末から送られた上りメッセージを受け取る。
+	// 最初のUpstreamRequestでgcm.Headerを送り、以降は処理を終えたメッセージごとにackを送る。
+	// ackを送らなかったメッセージはCCSが再送するので、messageIdで重複を取り除くこと。
+	ReceiveUpstream(BoltzGateway_ReceiveUpstreamServer) error
 }
 
 // UnimplementedBoltzGatewayServer can be embedded to have forward compatible implementations.
@@ -1141,6 +1648,12 @@ func (*UnimplementedBoltzGatewayServer) FetchStatistics(ctx context.Context, req
 func (*UnimplementedBoltzGatewayServer) FetchFeedback(req *apns.Header, srv BoltzGateway_FetchFeedbackServer) error {
 	return status.Errorf(codes.Unimplemented, "method FetchFeedback not implemented")
 }
+func (*UnimplementedBoltzGatewayServer) ManageChannel(ctx context.Context, req *apns.ChannelRequest) (*apns.ChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ManageChannel not implemented")
+}
+func (*UnimplementedBoltzGatewayServer) ReceiveUpstream(srv BoltzGateway_ReceiveUpstreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReceiveUpstream not implemented")
+}
 
 func RegisterBoltzGatewayServer(s *grpc.Server, srv BoltzGatewayServer) {
 	s.RegisterService(&_BoltzGateway_serviceDesc, srv)
@@ -1206,6 +1719,50 @@ func (x *boltzGatewayFetchFeedbackServer) Send(m *UnavailableTokenEvent) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _BoltzGateway_ManageChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(apns.ChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BoltzGatewayServer).ManageChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.BoltzGateway/ManageChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BoltzGatewayServer).ManageChannel(ctx, req.(*apns.ChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BoltzGateway_ReceiveUpstream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BoltzGatewayServer).ReceiveUpstream(&boltzGatewayReceiveUpstreamServer{stream})
+}
+
+type BoltzGateway_ReceiveUpstreamServer interface {
+	Send(*UpstreamMessage) error
+	Recv() (*UpstreamRequest, error)
+	grpc.ServerStream
+}
+
+type boltzGatewayReceiveUpstreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *boltzGatewayReceiveUpstreamServer) Send(m *UpstreamMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *boltzGatewayReceiveUpstreamServer) Recv() (*UpstreamRequest, error) {
+	m := new(UpstreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _BoltzGateway_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.BoltzGateway",
 	HandlerType: (*BoltzGatewayServer)(nil),
@@ -1214,6 +1771,10 @@ var _BoltzGateway_serviceDesc = grpc.ServiceDesc{
 			MethodName: "FetchStatistics",
 			Handler:    _BoltzGateway_FetchStatistics_Handler,
 		},
+		{
+			MethodName: "ManageChannel",
+			Handler:    _BoltzGateway_ManageChannel_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1226,6 +1787,12 @@ var _BoltzGateway_serviceDesc = grpc.ServiceDesc{
 			Handler:       _BoltzGateway_FetchFeedback_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ReceiveUpstream",
+			Handler:       _BoltzGateway_ReceiveUpstream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "master.proto",
 }